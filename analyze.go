@@ -0,0 +1,315 @@
+package router
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// IssueKind 为路由集合中存在的问题类型
+type IssueKind int
+
+const (
+	// Conflict 表示路由与已注册的路由冲突，注册时会panic
+	Conflict IssueKind = iota + 1
+	// Duplicate 表示同一method下重复注册了相同的路径模式
+	Duplicate
+	// Shadowed 表示路由能够注册但其匹配的请求会被其他路由处理
+	Shadowed
+	// SlashVariant 表示路由的路径添加或删除尾部'/'后的路径未注册，请求该路径时会被重定向到路由的路径
+	SlashVariant
+)
+
+func (k IssueKind) String() string {
+	switch k {
+	case Conflict:
+		return "conflict"
+	case Duplicate:
+		return "duplicate"
+	case Shadowed:
+		return "shadowed"
+	case SlashVariant:
+		return "slash variant"
+	}
+	return "unknown"
+}
+
+// Issue 为Analyze发现的一个问题
+type Issue struct {
+	Kind   IssueKind
+	Method string
+	Host   string
+	Path   string // 存在问题的路由的路径模式
+	Other  string // 与Path产生问题的另一个路由的路径模式，无法确定时为空
+	Detail string
+}
+
+func (i Issue) String() string {
+	s := i.Kind.String() + ": " + i.Method + " '" + i.Host + i.Path + "'"
+	if i.Other != "" {
+		s += " with '" + i.Other + "'"
+	}
+	if i.Detail != "" {
+		s += ": " + i.Detail
+	}
+	return s
+}
+
+// Report 为Analyze的结果
+type Report struct {
+	Issues []Issue
+}
+
+// OK 返回是否不存在会导致注册失败的问题
+func (r Report) OK() bool {
+	for _, v := range r.Issues {
+		if v.Kind == Conflict || v.Kind == Duplicate {
+			return false
+		}
+	}
+	return true
+}
+
+func (r Report) String() string {
+	lines := make([]string, 0, len(r.Issues))
+	for _, v := range r.Issues {
+		lines = append(lines, v.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Analyze 按顺序模拟注册routes，不会panic，返回其中存在的全部问题
+// 仅使用各路由的Method、Host和Path，Conflict和Duplicate按照路由在routes中的顺序排列，其后为按method、host排列的Shadowed和SlashVariant
+// 包含可选部分的路由按照展开后的各个路径分别检查，通过WithPriority设置了优先级的路由与Router相同地注册到后续的层中，不视为冲突
+func Analyze[H any](routes []Route[H]) Report {
+	var report Report
+	layers := make(map[treeKey][]*node)
+	accepted := make(map[treeKey][]entry) // 注册成功的路径
+
+	for i := range routes {
+		route := &routes[i]
+		key := treeKey{method: route.Method, host: route.Host}
+		issue := Issue{
+			Method: route.Method,
+			Host:   route.Host,
+			Path:   route.Path,
+		}
+
+		p, err := ParsePattern(route.Path)
+		if err != nil {
+			issue.Kind = Conflict
+			issue.Detail = err.Error()
+			report.Issues = append(report.Issues, issue)
+			continue
+		}
+
+		for _, path := range p.Expanded {
+			prefix := ""
+			if len(p.Expanded) > 1 {
+				prefix = "expanded to '" + path + "': "
+			}
+
+			if e, ok := findRegistered(accepted[key], path); ok {
+				issue.Kind = Duplicate
+				issue.Other = routes[e.route].Path
+				issue.Detail = prefix + "the path has been registered"
+				report.Issues = append(report.Issues, issue)
+				break
+			}
+
+			l := &leaf[H]{route: route, path: path, rank: specificity(path), seq: i}
+			var msg string
+			if layers[key], msg = registerLayered(layers[key], accepted[key], routes, l); msg != "" {
+				issue.Kind = Conflict
+				if e, ok := findConflict(accepted[key], path); ok {
+					issue.Other = routes[e.route].Path
+				}
+				issue.Detail = prefix + msg
+				report.Issues = append(report.Issues, issue)
+				break
+			}
+			accepted[key] = append(accepted[key], entry{route: i, path: path})
+		}
+	}
+
+	keys := make([]treeKey, 0, len(accepted))
+	for key := range accepted {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].method != keys[j].method {
+			return keys[i].method < keys[j].method
+		}
+		return keys[i].host < keys[j].host
+	})
+
+	var extra []Issue
+	for _, key := range keys {
+		registered := make(map[string]bool, len(accepted[key]))
+		for _, e := range accepted[key] {
+			registered[e.path] = true
+		}
+
+		for _, e := range accepted[key] {
+			route := &routes[e.route]
+			if l, _ := lookupLayers[H](layers[key], samplePath(e.path)); l != nil && l.route != route {
+				extra = append(extra, Issue{
+					Kind:   Shadowed,
+					Method: key.method,
+					Host:   key.host,
+					Path:   route.Path,
+					Other:  l.route.Path,
+					Detail: "requests matching the path are handled by another route",
+				})
+			}
+
+			// 只有一侧注册时，请求另一侧的路径会被重定向
+			if variant := toggleSlash(e.path); variant != "" && !registered[variant] {
+				if l, redirect := lookupLayers[H](layers[key], toggleSlash(samplePath(e.path))); l == nil && redirect {
+					extra = append(extra, Issue{
+						Kind:   SlashVariant,
+						Method: key.method,
+						Host:   key.host,
+						Path:   route.Path,
+						Detail: "requests to '" + variant + "' are redirected to '" + e.path + "'",
+					})
+				}
+			}
+		}
+	}
+	report.Issues = append(report.Issues, extra...)
+	return report
+}
+
+// entry 为注册成功的一个路径
+type entry struct {
+	route int    // 路由在routes中的下标
+	path  string // 展开可选部分后的路径
+}
+
+// 按照与trieRouter.registerExpanded相同的规则将l注册到layers中，accepted为已注册的路径，返回注册后的各层及冲突信息，注册成功时冲突信息为空串
+func registerLayered[H any](layers []*node, accepted []entry, routes []Route[H], l *leaf[H]) ([]*node, string) {
+	if len(layers) == 0 {
+		layers = []*node{{}}
+	}
+	msg := tryRegister(layers[0], l.path, l)
+	if msg == "" {
+		return layers, ""
+	}
+
+	if !l.route.hasPriority {
+		// 未设置优先级的路由之间不能冲突
+		var plain []string
+		hasPriority := false
+		for _, e := range accepted {
+			if routes[e.route].hasPriority {
+				hasPriority = true
+			} else {
+				plain = append(plain, e.path)
+			}
+		}
+		if !hasPriority {
+			return layers, msg
+		}
+		sort.Strings(plain)
+		root := &node{}
+		for _, path := range plain {
+			root.Register([]byte(path), path)
+		}
+		if msg := tryRegister(root, l.path, l); msg != "" {
+			return layers, msg
+		}
+	}
+
+	for _, root := range layers[1:] {
+		if tryRegister(root, l.path, l) == "" {
+			return layers, ""
+		}
+	}
+	root := &node{}
+	if msg := tryRegister(root, l.path, l); msg != "" {
+		return layers, msg
+	}
+	return append(layers, root), ""
+}
+
+// 返回layers中能够匹配path的路由中优先的一个，见trieRouter.match，未找到时第2个返回值表示是否需要重定向
+func lookupLayers[H any](layers []*node, path string) (*leaf[H], bool) {
+	var best *leaf[H]
+	redirect := false
+	for _, root := range layers {
+		h, _, tsr := root.Lookup([]byte(path))
+		if h == nil {
+			redirect = redirect || tsr
+			continue
+		}
+		if l := h.(*leaf[H]); best == nil || l.before(best) {
+			best = l
+		}
+	}
+	return best, redirect
+}
+
+// 将path注册到以root为根的树中，返回注册时panic的信息，注册成功时返回空串
+func tryRegister(root *node, path string, h interface{}) (errMsg string) {
+	defer func() {
+		if err := recover(); err != nil {
+			errMsg = fmt.Sprint(err)
+		}
+	}()
+	root.Register([]byte(path), h)
+	return ""
+}
+
+// 返回accepted中与path相同的路径
+func findRegistered(accepted []entry, path string) (entry, bool) {
+	for _, e := range accepted {
+		if e.path == path {
+			return e, true
+		}
+	}
+	return entry{}, false
+}
+
+// 在已注册的路径中查找单独与path一起注册即会冲突的路径，未找到或path本身非法时返回false
+func findConflict(accepted []entry, path string) (entry, bool) {
+	if verify([]byte(path)) != nil {
+		return entry{}, false
+	}
+	for _, e := range accepted {
+		root := &node{}
+		root.Register([]byte(e.path), e.path)
+		if tryRegister(root, path, path) != "" {
+			return e, true
+		}
+	}
+	return entry{}, false
+}
+
+// 返回添加或删除尾部'/'后的path，path为"/"时返回空串
+func toggleSlash(path string) string {
+	if path == "/" {
+		return ""
+	}
+	if strings.HasSuffix(path, "/") {
+		return path[:len(path)-1]
+	}
+	return path + "/"
+}
+
+// 返回一个能够被path匹配的请求路径，通配符段均替换为"x"
+func samplePath(path string) string {
+	buf := strings.Builder{}
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if !isWildcard(c) {
+			buf.WriteByte(c)
+			continue
+		}
+		buf.WriteByte('x')
+		for i+1 < len(path) && isParamNameChar(path[i+1]) {
+			i++
+		}
+	}
+	return buf.String()
+}
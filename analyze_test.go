@@ -0,0 +1,54 @@
+package router
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAnalyze(t *testing.T) {
+	Convey("Analyze", t, func() {
+		report := Analyze([]Route[interface{}]{
+			{Method: "GET", Path: "/users"},
+			{Method: "GET", Path: "/users/:id"},
+			{Method: "GET", Path: "/users/:name"},
+			{Method: "GET", Path: "/users"},
+			{Method: "GET", Path: "/files/*path"},
+			{Method: "GET", Path: "/files/"},
+			{Method: "GET", Path: "/docs/"},
+			{Method: "GET", Path: "/docs"},
+			{Method: "GET", Path: "/about/"},
+			{Method: "POST", Path: "bad"},
+		})
+		So(report.OK(), ShouldBeFalse)
+		So(report.String(), ShouldEqual, `conflict: GET '/users/:name' with '/users/:id': '/users/:name' conflict with the registered path '/users/:id'
+duplicate: GET '/users' with '/users': the path has been registered
+conflict: GET '/files/' with '/files/*path': '/files/' conflict with the registered path '/files/*path'
+conflict: POST 'bad': first char must be '/'
+slash variant: GET '/users/:id': requests to '/users/:id/' are redirected to '/users/:id'
+slash variant: GET '/about/': requests to '/about' are redirected to '/about/'`)
+
+		So(Analyze([]Route[interface{}]{{Method: "GET", Path: "/a"}, {Method: "GET", Path: "/a/:id"}}).OK(), ShouldBeTrue)
+
+		// 与Router相同，设置了优先级的路由注册到后续的层中
+		r := New()
+		r.Register("GET", "/users/:id", "user")
+		r.Register("GET", "/users/new", "new", WithPriority(1))
+		r.Register("GET", "/files/*path", "files", WithPriority(1))
+		r.Register("GET", "/files/:name", "file")
+		var routes []Route[interface{}]
+		r.Walk(func(route *Route[interface{}]) error {
+			routes = append(routes, *route)
+			return nil
+		})
+		report = Analyze(routes)
+		So(report.OK(), ShouldBeTrue)
+		So(report.String(), ShouldEqual, `slash variant: GET '/users/:id': requests to '/users/:id/' are redirected to '/users/:id'
+shadowed: GET '/files/:name' with '/files/*path': requests matching the path are handled by another route
+slash variant: GET '/users/new': requests to '/users/new/' are redirected to '/users/new'`)
+
+		report = Analyze(append(routes, Route[interface{}]{Method: "GET", Path: "/users/:name"}))
+		So(report.OK(), ShouldBeFalse)
+		So(report.Issues[0].Kind, ShouldEqual, Conflict)
+	})
+}
@@ -0,0 +1,79 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrUnauthenticated 表示请求未携带有效的身份信息，Handler返回401
+	ErrUnauthenticated = errors.New("unauthenticated")
+	// ErrForbidden 表示请求的身份不具有所需的权限，Handler返回403
+	ErrForbidden = errors.New("forbidden")
+)
+
+// Authorizer 检查请求是否具有路由声明的全部权限范围
+// 返回ErrUnauthenticated（或包装了该错误的错误）时Handler返回401，返回其他非nil错误时返回403
+type Authorizer interface {
+	Authorize(req *http.Request, scopes []string) error
+}
+
+// AuthorizerFunc 将函数适配为Authorizer
+type AuthorizerFunc func(req *http.Request, scopes []string) error
+
+func (f AuthorizerFunc) Authorize(req *http.Request, scopes []string) error {
+	return f(req, scopes)
+}
+
+// WithAuthorizer 设置Handler检查路由权限时使用的Authorizer，仅对通过WithScopes声明了权限范围的路由调用
+// 未设置Authorizer时，访问声明了权限范围的路由均返回403，避免遗漏配置导致受保护的路由被公开访问
+func WithAuthorizer(a Authorizer) HandlerOption {
+	return func(o *handlerOptions) {
+		o.authorizer = a
+	}
+}
+
+// WithRequirePolicy 使NewHandler在路由器中存在既未通过WithScopes声明权限范围、也未通过WithPublic声明为公开的路由时panic，
+// 用于在服务启动时发现遗漏了权限声明的路由，NewHandler之后注册的路由不会被检查
+func WithRequirePolicy() HandlerOption {
+	return func(o *handlerOptions) {
+		o.requirePolicy = true
+	}
+}
+
+// CheckPolicies 返回r中既未声明权限范围也未声明为公开的路由组成的错误，不存在时返回nil
+func CheckPolicies[H any](r TypedRouter[H]) error {
+	var missing []string
+	r.Walk(func(route *Route[H]) error {
+		if !route.public && len(route.scopes) == 0 {
+			missing = append(missing, "'"+route.Method+" "+route.Host+route.Path+"'")
+		}
+		return nil
+	})
+	if len(missing) == 0 {
+		return nil
+	}
+	return errors.New("routes without a declared policy: " + strings.Join(missing, ", "))
+}
+
+// 检查请求是否具有route所需的权限，不具有时写入401或403响应并返回false
+func (h *Handler[H]) authorize(rw http.ResponseWriter, req *http.Request, route *Route[H]) bool {
+	if len(route.scopes) == 0 {
+		return true
+	}
+
+	err := ErrForbidden
+	if h.opts.authorizer != nil {
+		err = h.opts.authorizer.Authorize(req, route.scopes)
+	}
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, ErrUnauthenticated):
+		http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+	default:
+		http.Error(rw, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+	}
+	return false
+}
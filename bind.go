@@ -0,0 +1,281 @@
+package router
+
+import (
+	"encoding"
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BindError 为将一个路径参数转换为结构体字段时发生的错误
+type BindError struct {
+	Field string // 结构体字段名，嵌入结构体中的字段以'.'连接
+	Param string
+	Value string
+	Err   error
+}
+
+func (e *BindError) Error() string {
+	return "bind param '" + e.Param + "' value '" + e.Value + "' to field '" + e.Field + "': " + e.Err.Error()
+}
+
+func (e *BindError) Unwrap() error {
+	return e.Err
+}
+
+// BindErrors 为一次绑定中发生的全部转换错误
+type BindErrors []*BindError
+
+func (l BindErrors) Error() string {
+	msgs := make([]string, 0, len(l))
+	for _, e := range l {
+		msgs = append(msgs, e.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// BindParams 根据结构体字段的path标签将params中的值转换后写入dst，dst必须为指向结构体的非nil指针
+// 标签的格式为`path:"name"`或`path:"name,layout=2006-01-02"`，layout仅用于time.Time类型的字段，标签为"-"或不存在时忽略该字段，
+// 支持的字段类型为实现了encoding.TextUnmarshaler的类型、字符串、整数、浮点数、布尔值、time.Time、time.Duration、
+// [16]byte（UUID的文本形式）以及指向以上类型的指针，嵌入的结构体中的字段同样会被绑定，
+// params中不存在的参数对应的字段保持不变，所有转换错误以BindErrors的形式一并返回
+func BindParams(params []UrlParam, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return errors.New("dst must be a non-nil pointer to struct")
+	}
+
+	plan, err := getBindPlan(v.Elem().Type())
+	if err != nil {
+		return err
+	}
+
+	var errs BindErrors
+	v = v.Elem()
+	for _, p := range params {
+		for i := range plan.fields {
+			f := &plan.fields[i]
+			if f.param != string(p.Key) {
+				continue
+			}
+			if err := f.set(v.FieldByIndex(f.index), string(p.Value), f.layout); err != nil {
+				errs = append(errs, &BindError{Field: f.name, Param: f.param, Value: string(p.Value), Err: err})
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// bindPlan 为一个结构体类型的绑定方式，按类型缓存以避免每次绑定时重复解析标签
+type bindPlan struct {
+	fields []bindField
+}
+
+type bindField struct {
+	name   string
+	param  string
+	layout string
+	index  []int
+	set    setter
+}
+
+// setter 将s转换后写入v，layout为字段标签中指定的时间格式
+type setter func(v reflect.Value, s, layout string) error
+
+type bindPlanEntry struct {
+	plan *bindPlan
+	err  error
+}
+
+var bindPlans sync.Map // reflect.Type -> *bindPlanEntry
+
+func getBindPlan(t reflect.Type) (*bindPlan, error) {
+	if e, ok := bindPlans.Load(t); ok {
+		return e.(*bindPlanEntry).plan, e.(*bindPlanEntry).err
+	}
+	plan := &bindPlan{}
+	err := plan.add(t, nil, "")
+	e, _ := bindPlans.LoadOrStore(t, &bindPlanEntry{plan: plan, err: err})
+	return e.(*bindPlanEntry).plan, e.(*bindPlanEntry).err
+}
+
+// 将结构体类型t中带有path标签的字段加入plan，index和prefix分别为t在外层结构体中的字段索引和字段名前缀
+func (plan *bindPlan) add(t reflect.Type, index []int, prefix string) error {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		idx := append(append([]int(nil), index...), i)
+		tag, ok := sf.Tag.Lookup("path")
+		if !ok {
+			ft := sf.Type
+			if sf.Anonymous && ft.Kind() == reflect.Struct {
+				if err := plan.add(ft, idx, prefix+sf.Name+"."); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if tag == "-" {
+			continue
+		}
+		if sf.PkgPath != "" {
+			return errors.New("the field '" + prefix + sf.Name + "' with the tag 'path' must be exported")
+		}
+
+		f := bindField{name: prefix + sf.Name, index: idx}
+		opts := strings.Split(tag, ",")
+		f.param = opts[0]
+		if f.param == "" {
+			f.param = sf.Name
+		}
+		for _, opt := range opts[1:] {
+			if !strings.HasPrefix(opt, "layout=") {
+				return errors.New("unknown option '" + opt + "' in the tag of the field '" + f.name + "'")
+			}
+			f.layout = strings.TrimPrefix(opt, "layout=")
+		}
+
+		f.set = newSetter(sf.Type)
+		if f.set == nil {
+			return errors.New("unsupported type '" + sf.Type.String() + "' of the field '" + f.name + "'")
+		}
+		plan.fields = append(plan.fields, f)
+	}
+	return nil
+}
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+)
+
+// 返回类型t对应的setter，不支持的类型返回nil
+func newSetter(t reflect.Type) setter {
+	if t.Kind() == reflect.Ptr {
+		elem := newSetter(t.Elem())
+		if elem == nil {
+			return nil
+		}
+		// 先解析到新分配的值中，成功后才赋给字段，解析失败时字段保持不变
+		return func(v reflect.Value, s, layout string) error {
+			p := reflect.New(t.Elem())
+			if err := elem(p.Elem(), s, layout); err != nil {
+				return err
+			}
+			v.Set(p)
+			return nil
+		}
+	}
+
+	switch {
+	case t == timeType:
+		return func(v reflect.Value, s, layout string) error {
+			if layout == "" {
+				layout = time.RFC3339
+			}
+			tm, err := time.Parse(layout, s)
+			if err != nil {
+				return err
+			}
+			v.Set(reflect.ValueOf(tm))
+			return nil
+		}
+	case t == durationType:
+		return func(v reflect.Value, s, _ string) error {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return err
+			}
+			v.SetInt(int64(d))
+			return nil
+		}
+	case reflect.PtrTo(t).Implements(textUnmarshalerType):
+		return func(v reflect.Value, s, _ string) error {
+			return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return func(v reflect.Value, s, _ string) error {
+			v.SetString(s)
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(v reflect.Value, s, _ string) error {
+			n, err := strconv.ParseInt(s, 10, t.Bits())
+			if err != nil {
+				return numError(err)
+			}
+			v.SetInt(n)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return func(v reflect.Value, s, _ string) error {
+			n, err := strconv.ParseUint(s, 10, t.Bits())
+			if err != nil {
+				return numError(err)
+			}
+			v.SetUint(n)
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		return func(v reflect.Value, s, _ string) error {
+			n, err := strconv.ParseFloat(s, t.Bits())
+			if err != nil {
+				return numError(err)
+			}
+			v.SetFloat(n)
+			return nil
+		}
+	case reflect.Bool:
+		return func(v reflect.Value, s, _ string) error {
+			b, err := strconv.ParseBool(s)
+			if err != nil {
+				return numError(err)
+			}
+			v.SetBool(b)
+			return nil
+		}
+	case reflect.Array:
+		if t.Len() == 16 && t.Elem().Kind() == reflect.Uint8 {
+			return func(v reflect.Value, s, _ string) error {
+				b, err := parseUUID(s)
+				if err != nil {
+					return err
+				}
+				reflect.Copy(v, reflect.ValueOf(b[:]))
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// 去掉strconv错误中重复的函数名及参数值
+func numError(err error) error {
+	if ne, ok := err.(*strconv.NumError); ok {
+		return ne.Err
+	}
+	return err
+}
+
+// 解析"xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx"形式的UUID
+func parseUUID(s string) ([16]byte, error) {
+	var ret [16]byte
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return ret, errors.New("invalid UUID format")
+	}
+	h := s[:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+	if _, err := hex.Decode(ret[:], []byte(h)); err != nil {
+		return ret, errors.New("invalid UUID format")
+	}
+	return ret, nil
+}
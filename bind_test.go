@@ -0,0 +1,103 @@
+package router
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type bindColor int
+
+func (c *bindColor) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "red":
+		*c = 1
+	case "blue":
+		*c = 2
+	default:
+		return errors.New("unknown color")
+	}
+	return nil
+}
+
+type bindPage struct {
+	Page int `path:"page"`
+}
+
+type bindTarget struct {
+	bindPage
+	ID      int64     `path:"id"`
+	Name    string    `path:"name"`
+	Score   float64   `path:"score"`
+	Active  bool      `path:"active"`
+	Day     time.Time `path:"day,layout=2006-01-02"`
+	At      time.Time `path:"at"`
+	TTL     time.Duration
+	Key     [16]byte  `path:"key"`
+	Color   bindColor `path:"color"`
+	Limit   *uint8    `path:"limit"`
+	Ignored string    `path:"-"`
+}
+
+func TestBindParams(t *testing.T) {
+	params := func(kv ...string) (ret []UrlParam) {
+		for i := 0; i < len(kv); i += 2 {
+			ret = append(ret, UrlParam{Key: []byte(kv[i]), Value: []byte(kv[i+1])})
+		}
+		return ret
+	}
+
+	Convey("BindParams", t, func() {
+		var dst bindTarget
+		err := BindParams(params(
+			"id", "42", "name", "a/b", "score", "1.5", "active", "true",
+			"day", "2022-02-05", "at", "2022-02-05T07:02:29Z", "TTL", "1m",
+			"key", "123e4567-e89b-12d3-a456-426614174000", "color", "blue", "limit", "8",
+			"page", "3", "-", "x",
+		), &dst)
+		So(err, ShouldBeNil)
+		So(dst.ID, ShouldEqual, 42)
+		So(dst.Name, ShouldEqual, "a/b")
+		So(dst.Score, ShouldEqual, 1.5)
+		So(dst.Active, ShouldBeTrue)
+		So(dst.Day, ShouldEqual, time.Date(2022, 2, 5, 0, 0, 0, 0, time.UTC))
+		So(dst.At, ShouldEqual, time.Date(2022, 2, 5, 7, 2, 29, 0, time.UTC))
+		So(dst.TTL, ShouldEqual, time.Duration(0))
+		So(dst.Key[:2], ShouldResemble, []byte{0x12, 0x3e})
+		So(dst.Color, ShouldEqual, 2)
+		So(*dst.Limit, ShouldEqual, 8)
+		So(dst.Page, ShouldEqual, 3)
+		So(dst.Ignored, ShouldBeEmpty)
+
+		err = BindParams(params("id", "x", "limit", "300", "color", "green", "name", "ok"), &dst)
+		So(err, ShouldNotBeNil)
+		errs, ok := err.(BindErrors)
+		So(ok, ShouldBeTrue)
+		So(len(errs), ShouldEqual, 3)
+		So(errs[0].Field, ShouldEqual, "ID")
+		So(err.Error(), ShouldEqual, strings.Join([]string{
+			"bind param 'id' value 'x' to field 'ID': invalid syntax",
+			"bind param 'limit' value '300' to field 'Limit': value out of range",
+			"bind param 'color' value 'green' to field 'Color': unknown color",
+		}, "; "))
+		So(dst.Name, ShouldEqual, "ok")
+		So(*dst.Limit, ShouldEqual, 8)
+
+		// 解析失败时指针字段同样保持不变
+		var empty bindTarget
+		So(BindParams(params("limit", "300"), &empty), ShouldNotBeNil)
+		So(empty.Limit, ShouldBeNil)
+
+		So(BindParams(nil, dst), ShouldBeError, "dst must be a non-nil pointer to struct")
+		So(BindParams(nil, (*bindTarget)(nil)), ShouldBeError, "dst must be a non-nil pointer to struct")
+		So(BindParams(nil, &struct {
+			C chan int `path:"c"`
+		}{}), ShouldBeError, "unsupported type 'chan int' of the field 'C'")
+		So(BindParams(nil, &struct {
+			id int `path:"id"`
+		}{}), ShouldBeError, "the field 'id' with the tag 'path' must be exported")
+	})
+}
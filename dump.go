@@ -0,0 +1,105 @@
+package router
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gogokit/treeprint"
+)
+
+func (r *trieRouter[H]) Dump() string {
+	keys := make([]treeKey, 0, len(r.trees))
+	for key := range r.trees {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].method != keys[j].method {
+			return keys[i].method < keys[j].method
+		}
+		return keys[i].host < keys[j].host
+	})
+
+	buf := strings.Builder{}
+	for _, key := range keys {
+		for i, root := range r.trees[key] {
+			if buf.Len() > 0 {
+				buf.WriteString("\n\n")
+			}
+			buf.WriteString(key.method)
+			if key.host != "" {
+				buf.WriteString(" " + key.host)
+			}
+			if len(r.trees[key]) > 1 {
+				buf.WriteString(" (layer " + fmt.Sprint(i) + ")")
+			}
+			buf.WriteString("\n" + renderWith(root, labelLeaf[H]))
+		}
+	}
+	return buf.String()
+}
+
+// 返回结点handler l的标记，l及l.variants中的每个路由对应一个标记，通过WithFormats声明了格式的路由在标记中列出格式，如" [#json]"
+func labelLeaf[H any](h interface{}) string {
+	l := h.(*leaf[H])
+	buf := strings.Builder{}
+	for _, v := range append([]*leaf[H]{l}, l.variants...) {
+		buf.WriteString(" [#" + strings.Join(v.route.formats, ",") + "]")
+	}
+	return buf.String()
+}
+
+type rNode struct {
+	ids   map[*node]string
+	n     *node
+	label func(h interface{}) string
+}
+
+func (n rNode) Id() string {
+	return n.ids[n.n]
+}
+
+func (n rNode) Children() (ret []treeprint.Node) {
+	for _, v := range n.n.children {
+		ret = append(ret, rNode{
+			ids:   n.ids,
+			n:     v,
+			label: n.label,
+		})
+	}
+	return ret
+}
+
+func (n rNode) String() string {
+	switch {
+	case n.n.handler == nil:
+		return string(n.n.path)
+	case n.label != nil:
+		return string(n.n.path) + n.label(n.n.handler)
+	default:
+		return string(n.n.path) + " [#]"
+	}
+}
+
+func genIdByDFS(root *node, ids map[*node]string) {
+	ids[root] = fmt.Sprintf("%d", len(ids))
+	for _, v := range root.children {
+		genIdByDFS(v, ids)
+	}
+}
+
+// 返回以n为根的树的文本表示，存在handler的结点带有" [#]"标记
+func render(n *node) string {
+	return renderWith(n, nil)
+}
+
+// 与render相同，但存在handler的结点的标记由label返回，label为nil时为" [#]"
+func renderWith(n *node, label func(h interface{}) string) string {
+	ids := make(map[*node]string)
+	genIdByDFS(n, ids)
+	return treeprint.Print(rNode{
+		ids:   ids,
+		n:     n,
+		label: label,
+	}, 4)
+}
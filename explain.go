@@ -0,0 +1,202 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// Trace 为一次查找的详细过程，用于排查请求未命中或命中了错误的路由的原因
+type Trace struct {
+	Method       string            `json:"method"`
+	Host         string            `json:"host,omitempty"`
+	Path         string            `json:"path"`                    // 用于查找的路径，经过重写时为重写后的路径
+	OriginalPath string            `json:"original_path,omitempty"` // 重写前的路径，未经过重写时为空
+	Steps        []TraceStep       `json:"steps"`
+	Pattern      string            `json:"pattern,omitempty"` // 命中路由的路径模式，未命中时为空
+	Params       map[string]string `json:"params,omitempty"`
+	Format       string            `json:"format,omitempty"`   // 从路径中分离出的格式后缀，见Match.Format
+	Redirect     bool              `json:"redirect,omitempty"` // 未命中时表示存在path添加或删除尾部'/'后的路径对应的路由
+	Reason       string            `json:"reason,omitempty"`   // 未命中时的原因，存在多层树时为各层未命中的原因
+}
+
+// TraceStep 为查找过程中对一个结点的访问
+type TraceStep struct {
+	Host   string `json:"host,omitempty"`   // 结点所在树对应的host，见ExplainHost
+	Layer  int    `json:"layer"`            // 结点所在树的层数，见WithPriority
+	Node   string `json:"node"`             // 结点的路径
+	Path   string `json:"path"`             // 到达结点时剩余待匹配的路径
+	Prefix string `json:"prefix,omitempty"` // 与结点路径比较后匹配的前缀，对于通配符结点为参数值
+	Branch string `json:"branch,omitempty"` // 离开结点时选择的分支
+}
+
+// 记录查找的过程，方法均可以在nil上调用，此时不做任何操作
+type tracer struct {
+	host   string
+	layer  int
+	steps  []TraceStep
+	reason string
+}
+
+func (t *tracer) visit(n *node, path []byte) {
+	if t == nil {
+		return
+	}
+	t.steps = append(t.steps, TraceStep{
+		Host:  t.host,
+		Layer: t.layer,
+		Node:  string(n.path),
+		Path:  string(path),
+	})
+}
+
+func (t *tracer) prefix(prefix []byte) {
+	if t == nil || len(t.steps) == 0 {
+		return
+	}
+	t.steps[len(t.steps)-1].Prefix = string(prefix)
+}
+
+func (t *tracer) branch(branch string) {
+	if t == nil || len(t.steps) == 0 {
+		return
+	}
+	t.steps[len(t.steps)-1].Branch = branch
+}
+
+func (t *tracer) fail(reason string) {
+	if t == nil {
+		return
+	}
+	t.reason = reason
+}
+
+func (r *trieRouter[H]) Explain(method, path string) Trace {
+	return r.ExplainHost(method, "", path)
+}
+
+func (r *trieRouter[H]) ExplainHost(method, host, path string) Trace {
+	trace := Trace{Method: method, Host: host, Path: path}
+	if r.opts.matrixParams {
+		path, _ = stripMatrix(path)
+		trace.Path = path
+	}
+	target, err := r.rewrite(method, path)
+	if err != nil {
+		trace.Reason = err.Error()
+		return trace
+	}
+	if target != path {
+		trace.Path, trace.OriginalPath = target, path
+		path = target
+	}
+
+	// 与MatchHost相同，开启WithFormatSuffix时优先去掉格式后缀后查找
+	if base, format := splitFormat(path, r.opts.formats); format != "" {
+		t := trace
+		if l := r.explainHost(method, host, base, format, &t); l != nil && l.acceptsSuffix() {
+			t.Format = format
+			return t
+		}
+	}
+	r.explainHost(method, host, path, "", &trace)
+	return trace
+}
+
+// 与MatchHost相同，优先在host对应的树中查找，未命中时再在不带host的树中查找，返回命中的结点handler
+func (r *trieRouter[H]) explainHost(method, host, path, format string, trace *Trace) *leaf[H] {
+	if host == "" {
+		return r.explain(treeKey{method: method}, path, format, trace)
+	}
+
+	if l := r.explain(treeKey{method: method, host: host}, path, format, trace); l != nil {
+		return l
+	}
+	redirect := trace.Redirect
+	l := r.explain(treeKey{method: method}, path, format, trace)
+	if l == nil {
+		trace.Redirect = trace.Redirect || redirect
+	}
+	return l
+}
+
+// 在key对应的各层树中查找path，将访问的结点及未命中的原因追加到trace中，返回命中的结点handler
+// format为从路径中分离出的格式后缀，见WithFormatSuffix
+func (r *trieRouter[H]) explain(key treeKey, path, format string, trace *Trace) *leaf[H] {
+	reason := func(s string) {
+		if key.host != "" {
+			s = "host '" + key.host + "': " + s
+		}
+		if trace.Reason != "" {
+			trace.Reason += "; "
+		}
+		trace.Reason += s
+	}
+
+	trees := r.trees[key]
+	if len(trees) == 0 {
+		reason("no route registered for the method '" + key.method + "'")
+		return nil
+	}
+
+	var best *leaf[H]
+	var bestParams []UrlParam
+	t := &tracer{host: key.host, steps: trace.Steps}
+	for i, root := range trees {
+		t.layer, t.reason = i, ""
+		h, p, redirect := root.lookup([]byte(path), t)
+		var l *leaf[H]
+		if h != nil {
+			if l = h.(*leaf[H]).resolve(format); l == nil {
+				t.fail("every route of the matched path requires a format suffix")
+			}
+		}
+		if l == nil {
+			trace.Redirect = trace.Redirect || redirect
+			if len(trees) > 1 && t.reason != "" {
+				t.reason = "layer " + strconv.Itoa(i) + ": " + t.reason
+			}
+			reason(t.reason)
+			continue
+		}
+		if best == nil || l.before(best) {
+			best, bestParams = l, p
+		}
+	}
+	trace.Steps = t.steps
+
+	if best == nil {
+		return nil
+	}
+	m := newMatch(best, bestParams)
+	trace.Pattern = m.Route.Path
+	trace.Redirect = false
+	trace.Reason = ""
+	trace.Params = make(map[string]string, len(m.Params))
+	for _, p := range m.Params {
+		trace.Params[string(p.Key)] = string(p.Value)
+	}
+	return best
+}
+
+// ExplainHandler 返回以JSON格式输出ExplainHost结果的http.Handler，查找的method、host和path分别由查询参数method、host和path指定，method默认为GET
+// 返回的handler会暴露路由的内部结构，应当只挂载在受保护的调试地址上
+func ExplainHandler[H any](r TypedRouter[H]) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		q := req.URL.Query()
+		method := q.Get("method")
+		if method == "" {
+			method = http.MethodGet
+		}
+		path := q.Get("path")
+		if path == "" {
+			http.Error(rw, "the query parameter 'path' is required", http.StatusBadRequest)
+			return
+		}
+
+		rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+		enc := json.NewEncoder(rw)
+		enc.SetIndent("", "  ")
+		enc.Encode(r.ExplainHost(method, q.Get("host"), path))
+	})
+}
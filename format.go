@@ -0,0 +1,176 @@
+package router
+
+import "strings"
+
+// WithFormatSuffix 开启格式后缀模式：查找时如果最后一个路径段以".ext"结尾且ext为exts之一，则先去掉该后缀后查找，
+// 命中时Match.Format为ext，未命中时再查找完整的路径
+// 路由可以通过WithFormats声明只处理某些格式，从而使"/reports/1.json"和"/reports/1.csv"命中路径同为"/reports/:id"的不同路由，
+// 未声明格式的路由处理其他格式及不带后缀的请求，可以通过Match.Format区分
+// 以'*'通配符结尾且未声明格式的路由不分离后缀，例如"/static/*file"匹配"/static/a/b.json"时file为"a/b.json"
+// 开启后以格式后缀结尾的路由视为在去掉后缀的路径上通过WithFormats声明了该格式，例如"/reports/:id.json"等价于WithFormats("json")的"/reports/:id"，
+// "/openapi.json"等价于WithFormats("json")的"/openapi"，Route.Path仍为注册时的路径
+func WithFormatSuffix(exts ...string) RouterOption {
+	if len(exts) == 0 {
+		panic("format suffixes must not be empty")
+	}
+	for _, ext := range exts {
+		if !isFormat(ext) {
+			panic("invalid format suffix '" + ext + "'")
+		}
+	}
+	return func(o *routerOptions) {
+		if o.formats == nil {
+			o.formats = make(map[string]bool, len(exts))
+		}
+		for _, ext := range exts {
+			o.formats[ext] = true
+		}
+	}
+}
+
+// WithFormats 声明路由只处理带有exts中格式后缀的请求，路由器必须通过WithFormatSuffix开启这些格式
+func WithFormats(exts ...string) RouteOption {
+	if len(exts) == 0 {
+		panic("formats must not be empty")
+	}
+	exts = append([]string(nil), exts...)
+	return func(o *routeOptions) {
+		o.formats = append(o.formats, exts...)
+	}
+}
+
+// Formats 返回通过WithFormats为路由声明的格式后缀
+func (o *routeOptions) Formats() []string {
+	return append([]string(nil), o.formats...)
+}
+
+// 检查route的格式声明及展开后的路径paths是否与路由器开启的格式后缀冲突
+func (r *trieRouter[H]) verifyFormats(source string, paths []string, route *Route[H]) {
+	for _, f := range route.formats {
+		if r.opts.formats == nil {
+			panic("WithFormats requires the router option WithFormatSuffix")
+		}
+		if !r.opts.formats[f] {
+			panic("the format '" + f + "' is not enabled by WithFormatSuffix")
+		}
+	}
+
+	if r.opts.formats == nil {
+		return
+	}
+	for _, path := range paths {
+		// 路由路径末尾的格式后缀已经由literalFormat分离，别名以格式后缀结尾时无法为其单独声明格式
+		if _, f := splitFormat(path, r.opts.formats); f != "" {
+			panic("'" + source + "' ends with the format suffix '." + f + "', use WithFormats instead")
+		}
+	}
+}
+
+// 分离p展开后的各个路径末尾的格式后缀并返回去掉后缀后的路径，存在后缀时将其作为route通过WithFormats声明的格式
+// 各个路径的后缀必须相同，route已经通过WithFormats声明了其他格式时panic
+func literalFormat[H any](p Pattern, route *Route[H], formats map[string]bool) []string {
+	var format string
+	paths := make([]string, len(p.Expanded))
+	for i, path := range p.Expanded {
+		base, f := splitFormat(path, formats)
+		if i > 0 && f != format {
+			panic("the expanded paths of '" + p.Source + "' must end with the same format suffix")
+		}
+		paths[i], format = base, f
+	}
+	if format == "" {
+		return p.Expanded
+	}
+	if len(route.formats) > 0 && !(len(route.formats) == 1 && route.formats[0] == format) {
+		panic("'" + p.Source + "' ends with the format suffix '." + format + "' and must not declare other formats with WithFormats")
+	}
+	route.formats = []string{format}
+	return paths
+}
+
+// 将path最后一个路径段中的格式后缀分离出来，返回去掉后缀的路径及后缀，不存在formats中的后缀时返回path及空串
+func splitFormat(path string, formats map[string]bool) (string, string) {
+	if len(formats) == 0 {
+		return path, ""
+	}
+	i := strings.LastIndexByte(path, '.')
+	if i < 0 || strings.IndexByte(path[i:], '/') >= 0 || i == 0 || path[i-1] == '/' {
+		return path, ""
+	}
+	if f := path[i+1:]; formats[f] {
+		return path[:i], f
+	}
+	return path, ""
+}
+
+// 返回l及l.variants中能够处理格式后缀format的全部handler，format为空时为未声明格式的路由
+func (l *leaf[H]) handles(format string) []*leaf[H] {
+	var ret []*leaf[H]
+	for _, v := range append([]*leaf[H]{l}, l.variants...) {
+		if format == "" && len(v.route.formats) == 0 ||
+			format != "" && (containsString(v.route.formats, format) || len(v.route.formats) == 0 && v.acceptsSuffix()) {
+			ret = append(ret, v)
+		}
+	}
+	return ret
+}
+
+// 返回去掉格式后缀后命中的l能否处理带有该后缀的请求：以'*'通配符结尾且未通过WithFormats声明格式的路由，其通配符的值应当包含后缀
+func (l *leaf[H]) acceptsSuffix() bool {
+	return len(l.route.formats) > 0 || l.route.catchAll == "" && !endsWithCatchAll(l.path)
+}
+
+// 返回path的最后一个路径段是否为'*'通配符
+func endsWithCatchAll(path string) bool {
+	seg := path[strings.LastIndexByte(path, '/')+1:]
+	return isCatchAll(seg)
+}
+
+// 返回路径段seg是否为带有名称的'*'通配符，不带名称的'*'只匹配单个路径段
+func isCatchAll(seg string) bool {
+	return len(seg) > 1 && seg[0] == '*' && isParamNameChar(seg[1])
+}
+
+func isFormat(ext string) bool {
+	return ext != "" && isParamName(ext)
+}
+
+// 将路径与l相同的v加入l.variants，v与l及已有的variants声明的格式不能重叠，且至多一个路由不声明格式
+func (l *leaf[H]) addVariant(v *leaf[H]) {
+	for i := -1; i < len(l.variants); i++ {
+		o := l
+		if i >= 0 {
+			o = l.variants[i]
+		}
+		if len(o.route.formats) == 0 && len(v.route.formats) == 0 {
+			panic("the current path '" + v.path + "' handler has been registered")
+		}
+		for _, f := range v.route.formats {
+			if containsString(o.route.formats, f) {
+				panic("the format '" + f + "' of '" + v.path + "' conflict with the route '" + o.route.Method + " " + o.route.Path + "'")
+			}
+		}
+	}
+	l.variants = append(l.variants, v)
+}
+
+// 返回l及l.variants中处理格式后缀format的handler：优先返回声明了该格式的路由，其次返回未声明格式的路由，均不存在时返回nil
+func (l *leaf[H]) resolve(format string) *leaf[H] {
+	var def *leaf[H]
+	for i := -1; i < len(l.variants); i++ {
+		v := l
+		if i >= 0 {
+			v = l.variants[i]
+		}
+		if len(v.route.formats) == 0 {
+			if def == nil {
+				def = v
+			}
+			continue
+		}
+		if format != "" && containsString(v.route.formats, format) {
+			return v
+		}
+	}
+	return def
+}
@@ -1,8 +1,16 @@
 module github.com/gogokit/router
 
-go 1.16
+go 1.18
 
 require (
+	github.com/BurntSushi/toml v1.2.1
 	github.com/gogokit/treeprint v0.0.0-20220205070229-341a7b457e94
 	github.com/smartystreets/goconvey v1.6.4
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/gopherjs/gopherjs v0.0.0-20181017120253-0766667cb4d1 // indirect
+	github.com/jtolds/gls v4.20.0+incompatible // indirect
+	github.com/smartystreets/assertions v0.0.0-20180927180507-b2de0cb4f26d // indirect
 )
@@ -0,0 +1,264 @@
+package router
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// HandlerFunc 为可以直接获取路径参数的http处理函数
+type HandlerFunc func(rw http.ResponseWriter, r *http.Request, params []UrlParam)
+
+// Handler 将路由器适配为http.Handler
+// 注册的handler必须为HandlerFunc、func(http.ResponseWriter, *http.Request, []UrlParam)、http.Handler或func(http.ResponseWriter, *http.Request)
+type Handler[H any] struct {
+	router TypedRouter[H]
+	opts   handlerOptions
+}
+
+// HandlerOption 用于设置Handler的行为
+type HandlerOption func(o *handlerOptions)
+
+type handlerOptions struct {
+	paramsInContext bool
+	notFound        http.Handler
+	noRedirect      bool
+	suggestions     int
+	authorizer      Authorizer
+	requirePolicy   bool
+	errorHandler    func(rw http.ResponseWriter, req *http.Request, err error)
+}
+
+// WithParamsInContext 将路径参数、矩阵参数、格式后缀和命中路由的路径模式存入请求的context，可通过ParamsFromContext、MatrixFromContext、FormatFromContext和PatternFromContext获取
+func WithParamsInContext() HandlerOption {
+	return func(o *handlerOptions) {
+		o.paramsInContext = true
+	}
+}
+
+// WithNotFound 设置未找到路由时使用的handler，默认为http.NotFoundHandler()
+func WithNotFound(h http.Handler) HandlerOption {
+	return func(o *handlerOptions) {
+		o.notFound = h
+	}
+}
+
+// WithoutRedirect 关闭默认开启的尾部'/'重定向
+func WithoutRedirect() HandlerOption {
+	return func(o *handlerOptions) {
+		o.noRedirect = true
+	}
+}
+
+// WithSuggestions 在默认的404响应中列出至多n个与请求路径最接近的路由，设置了WithNotFound时不生效
+// 404响应不经过认证，因此只列出通过WithPublic声明的路由，避免泄露需要权限的路由
+func WithSuggestions(n int) HandlerOption {
+	return func(o *handlerOptions) {
+		o.suggestions = n
+	}
+}
+
+// WithErrorHandler 设置查找出错时调用的函数，如重写规则形成循环，见Match.RewriteErr
+// 默认返回状态码为500的响应，错误信息属于路由配置的内部信息，不会返回给客户端，可以在fn中记录日志
+func WithErrorHandler(fn func(rw http.ResponseWriter, req *http.Request, err error)) HandlerOption {
+	return func(o *handlerOptions) {
+		o.errorHandler = fn
+	}
+}
+
+func NewHandler[H any](r TypedRouter[H], opts ...HandlerOption) *Handler[H] {
+	h := &Handler[H]{router: r}
+	for _, opt := range opts {
+		opt(&h.opts)
+	}
+	if h.opts.requirePolicy {
+		if err := CheckPolicies(r); err != nil {
+			panic(err.Error())
+		}
+	}
+	if h.opts.errorHandler == nil {
+		h.opts.errorHandler = func(rw http.ResponseWriter, req *http.Request, err error) {
+			http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+	}
+	if h.opts.notFound == nil {
+		h.opts.notFound = http.NotFoundHandler()
+		if h.opts.suggestions > 0 {
+			h.opts.notFound = h.suggestNotFound()
+		}
+	}
+	return h
+}
+
+func (h *Handler[H]) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	m := h.router.MatchHost(req.Method, stripPort(req.Host), req.URL.Path)
+	if m.RewriteErr != nil {
+		h.opts.errorHandler(rw, req, m.RewriteErr)
+		return
+	}
+	if m.Route == nil {
+		if m.Redirect && !h.opts.noRedirect {
+			redirectTrailingSlash(rw, req)
+			return
+		}
+		h.opts.notFound.ServeHTTP(rw, req)
+		return
+	}
+
+	if rule := m.Route.redirect; rule != nil {
+		http.Redirect(rw, req, rule.Location(m.Params, req.URL.RawQuery), rule.Code)
+		return
+	}
+
+	if m.Deprecated {
+		rw.Header().Set("Deprecation", "true")
+	}
+
+	if !h.authorize(rw, req, m.Route) {
+		return
+	}
+
+	if m.OriginalPath != "" {
+		// 使handler看到重写后的路径，原始路径可以通过OriginalPathFromContext获取
+		u := *req.URL
+		u.Path, u.RawPath = m.Path, ""
+		req = req.WithContext(context.WithValue(req.Context(), originalPathKey{}, m.OriginalPath))
+		req.URL = &u
+	}
+
+	if h.opts.paramsInContext {
+		req = req.WithContext(context.WithValue(req.Context(), routeContextKey{}, &routeContext{
+			pattern: m.Route.Path,
+			params:  m.Params,
+			matrix:  m.Matrix,
+			format:  m.Format,
+		}))
+	}
+
+	serve(m.Route.Handler, rw, req, m.Params)
+}
+
+// 返回在404响应中列出相近路由的handler
+func (h *Handler[H]) suggestNotFound() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		patterns := h.router.SuggestPublic(req.Method, req.URL.Path, h.opts.suggestions)
+		if len(patterns) == 0 {
+			http.NotFound(rw, req)
+			return
+		}
+
+		buf := strings.Builder{}
+		buf.WriteString("404 page not found\n\ndid you mean:\n")
+		for _, p := range patterns {
+			buf.WriteString("  " + req.Method + " " + p + "\n")
+		}
+		rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		rw.Header().Set("X-Content-Type-Options", "nosniff")
+		rw.WriteHeader(http.StatusNotFound)
+		rw.Write([]byte(buf.String()))
+	})
+}
+
+// 返回去掉端口后的host
+func stripPort(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return host
+}
+
+// 调用handler处理请求
+func serve(handler interface{}, rw http.ResponseWriter, req *http.Request, params []UrlParam) {
+	switch h := handler.(type) {
+	case HandlerFunc:
+		h(rw, req, params)
+	case func(http.ResponseWriter, *http.Request, []UrlParam):
+		h(rw, req, params)
+	case http.Handler:
+		h.ServeHTTP(rw, req)
+	case func(http.ResponseWriter, *http.Request):
+		h(rw, req)
+	default:
+		http.Error(rw, "unsupported handler type", http.StatusInternalServerError)
+	}
+}
+
+// 添加或删除请求路径的尾部'/'后重定向，GET请求使用301，其他请求使用308以保留请求方法和请求体
+// 路径开头连续的'/'会合并为一个，避免"//host/"这类请求被重定向到其他host
+func redirectTrailingSlash(rw http.ResponseWriter, req *http.Request) {
+	u := *req.URL
+	if n := len(u.Path); n > 1 && u.Path[n-1] == '/' {
+		u.Path = u.Path[:n-1]
+	} else {
+		u.Path += "/"
+	}
+	u.Path = cleanLeadingSlashes(u.Path)
+	u.RawPath = ""
+
+	code := http.StatusMovedPermanently
+	if req.Method != http.MethodGet {
+		code = http.StatusPermanentRedirect
+	}
+	http.Redirect(rw, req, u.String(), code)
+}
+
+type routeContextKey struct{}
+
+// routeContext 为存入请求context的路由信息，每个请求仅分配一次
+type routeContext struct {
+	pattern string
+	params  []UrlParam
+	matrix  [][]UrlParam
+	format  string
+}
+
+// ParamsFromContext 返回通过WithParamsInContext存入ctx的路径参数
+func ParamsFromContext(ctx context.Context) []UrlParam {
+	if rc, ok := ctx.Value(routeContextKey{}).(*routeContext); ok {
+		return rc.params
+	}
+	return nil
+}
+
+// MatrixFromContext 返回通过WithParamsInContext存入ctx的各路径段的矩阵参数，见Match.Matrix
+func MatrixFromContext(ctx context.Context) [][]UrlParam {
+	if rc, ok := ctx.Value(routeContextKey{}).(*routeContext); ok {
+		return rc.matrix
+	}
+	return nil
+}
+
+// FormatFromContext 返回通过WithParamsInContext存入ctx的格式后缀，见Match.Format
+func FormatFromContext(ctx context.Context) string {
+	if rc, ok := ctx.Value(routeContextKey{}).(*routeContext); ok {
+		return rc.format
+	}
+	return ""
+}
+
+// PatternFromContext 返回通过WithParamsInContext存入ctx的命中路由的路径模式，不存在时返回空串
+func PatternFromContext(ctx context.Context) string {
+	if rc, ok := ctx.Value(routeContextKey{}).(*routeContext); ok {
+		return rc.pattern
+	}
+	return ""
+}
+
+type originalPathKey struct{}
+
+// OriginalPathFromContext 返回经过重写的请求在重写前的路径，请求未经过重写时返回空串
+func OriginalPathFromContext(ctx context.Context) string {
+	path, _ := ctx.Value(originalPathKey{}).(string)
+	return path
+}
+
+// ParamValue 返回params中key对应的值，不存在时返回空串
+func ParamValue(params []UrlParam, key string) string {
+	for _, p := range params {
+		if string(p.Key) == key {
+			return string(p.Value)
+		}
+	}
+	return ""
+}
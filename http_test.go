@@ -0,0 +1,191 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestHandler(t *testing.T) {
+	Convey("Handler", t, func() {
+		r := New()
+		r.Register("GET", "/users/:id", func(rw http.ResponseWriter, req *http.Request, params []UrlParam) {
+			rw.Write([]byte("user " + ParamValue(params, "id")))
+		})
+		r.Register("GET", "/files/*path", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.Write([]byte(PatternFromContext(req.Context()) + " " + ParamValue(ParamsFromContext(req.Context()), "path")))
+		}))
+		r.Register("POST", "/docs/", func(rw http.ResponseWriter, req *http.Request) {})
+
+		do := func(h http.Handler, method, target string) *httptest.ResponseRecorder {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+			return rec
+		}
+
+		Convey("params", func() {
+			h := NewHandler(r)
+			So(do(h, "GET", "/users/1").Body.String(), ShouldEqual, "user 1")
+			So(do(h, "GET", "/files/a/b").Body.String(), ShouldEqual, " ")
+			So(do(h, "GET", "/missing").Code, ShouldEqual, http.StatusNotFound)
+		})
+
+		Convey("context", func() {
+			h := NewHandler(r, WithParamsInContext())
+			So(do(h, "GET", "/files/a/b").Body.String(), ShouldEqual, "/files/*path a/b")
+		})
+
+		Convey("redirect", func() {
+			rec := do(NewHandler(r), "GET", "/users/1/?q=1")
+			So(rec.Code, ShouldEqual, http.StatusMovedPermanently)
+			So(rec.Header().Get("Location"), ShouldEqual, "/users/1?q=1")
+
+			rec = do(NewHandler(r), "POST", "/docs")
+			So(rec.Code, ShouldEqual, http.StatusPermanentRedirect)
+			So(rec.Header().Get("Location"), ShouldEqual, "/docs/")
+
+			So(do(NewHandler(r, WithoutRedirect()), "POST", "/docs").Code, ShouldEqual, http.StatusNotFound)
+
+			// 重定向到包含矩阵参数的原始路径
+			mr := New(WithMatrixParams())
+			mr.Register("GET", "/cars/models", func(rw http.ResponseWriter, req *http.Request) {})
+			rec = do(NewHandler(mr), "GET", "/cars;a=1/models/")
+			So(rec.Code, ShouldEqual, http.StatusMovedPermanently)
+			So(rec.Header().Get("Location"), ShouldEqual, "/cars;a=1/models")
+
+			// 以"//"开头的请求路径不能被重定向到其他host
+			pr := New()
+			pr.Register("GET", "/:a/:b", func(rw http.ResponseWriter, req *http.Request) {})
+			rec = do(NewHandler(pr), "GET", "//evil.com/")
+			So(rec.Code, ShouldEqual, http.StatusMovedPermanently)
+			So(rec.Header().Get("Location"), ShouldEqual, "/evil.com")
+		})
+
+		Convey("suggestions", func() {
+			// 只列出通过WithPublic声明的路由
+			So(do(NewHandler(r, WithSuggestions(1)), "GET", "/user/1").Body.String(), ShouldEqual, "404 page not found\n")
+
+			r := New()
+			r.Register("GET", "/users/:id", func(rw http.ResponseWriter, req *http.Request) {}, WithPublic())
+			r.Register("GET", "/user/:id/secrets", func(rw http.ResponseWriter, req *http.Request) {}, WithScopes("admin"))
+			rec := do(NewHandler(r, WithSuggestions(2)), "GET", "/user/1")
+			So(rec.Code, ShouldEqual, http.StatusNotFound)
+			So(rec.Body.String(), ShouldEqual, "404 page not found\n\ndid you mean:\n  GET /users/:id\n")
+			So(do(NewHandler(r, WithSuggestions(2)), "GET", "/x").Body.String(), ShouldEqual, "404 page not found\n")
+		})
+
+		Convey("authorize", func() {
+			r := New()
+			r.Register("GET", "/admin", func(rw http.ResponseWriter, req *http.Request) {
+				rw.Write([]byte("admin"))
+			}, WithScopes("admin"))
+			r.Register("GET", "/public", func(rw http.ResponseWriter, req *http.Request) {}, WithPublic())
+
+			var scopes []string
+			h := NewHandler(r, WithAuthorizer(AuthorizerFunc(func(req *http.Request, s []string) error {
+				scopes = s
+				switch req.Header.Get("Authorization") {
+				case "":
+					return ErrUnauthenticated
+				case "admin":
+					return nil
+				}
+				return ErrForbidden
+			})))
+			So(do(h, "GET", "/admin").Code, ShouldEqual, http.StatusUnauthorized)
+			So(scopes, ShouldResemble, []string{"admin"})
+
+			req := httptest.NewRequest("GET", "/admin", nil)
+			req.Header.Set("Authorization", "user")
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			So(rec.Code, ShouldEqual, http.StatusForbidden)
+
+			req.Header.Set("Authorization", "admin")
+			rec = httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			So(rec.Body.String(), ShouldEqual, "admin")
+
+			scopes = nil
+			So(do(h, "GET", "/public").Code, ShouldEqual, http.StatusOK)
+			So(scopes, ShouldBeNil)
+
+			So(do(NewHandler(r), "GET", "/admin").Code, ShouldEqual, http.StatusForbidden)
+			So(func() { NewHandler(r, WithRequirePolicy()) }, ShouldNotPanic)
+
+			r.Register("GET", "/open", func(rw http.ResponseWriter, req *http.Request) {})
+			So(func() { NewHandler(r, WithRequirePolicy()) }, ShouldPanicWith, "routes without a declared policy: 'GET /open'")
+		})
+
+		Convey("alias", func() {
+			r.Register("GET", "/posts/:id", func(rw http.ResponseWriter, req *http.Request) {}, WithName("post"))
+			r.Alias("post", "/v1/posts/:id", DeprecatedAlias())
+			h := NewHandler(r)
+			So(do(h, "GET", "/v1/posts/1").Header().Get("Deprecation"), ShouldEqual, "true")
+			So(do(h, "GET", "/posts/1").Header().Get("Deprecation"), ShouldBeEmpty)
+		})
+
+		Convey("redirect rule", func() {
+			r.Redirect("GET", "/old/users/:id", "/users/:id", http.StatusMovedPermanently)
+			r.Redirect("", "POST /old/files/*path", "https://files.example.com/v2/*path?from=old", http.StatusPermanentRedirect)
+			h := NewHandler(r)
+
+			rec := do(h, "GET", "/old/users/a%20b?x=1")
+			So(rec.Code, ShouldEqual, http.StatusMovedPermanently)
+			So(rec.Header().Get("Location"), ShouldEqual, "/users/a%20b?x=1")
+
+			// '*'参数的值以'/'开头时不能生成指向其他host的Location
+			r.Redirect("GET", "/legacy/*rest", "/*rest", http.StatusFound)
+			rec = do(h, "GET", "/legacy//evil.com/x")
+			So(rec.Code, ShouldEqual, http.StatusFound)
+			So(rec.Header().Get("Location"), ShouldEqual, "/evil.com/x")
+
+			rec = do(h, "POST", "/old/files/a/b?x=1")
+			So(rec.Code, ShouldEqual, http.StatusPermanentRedirect)
+			So(rec.Header().Get("Location"), ShouldEqual, "https://files.example.com/v2/a/b?from=old")
+		})
+
+		Convey("rewrite", func() {
+			r.Register("GET", "/api/v3/*rest", func(rw http.ResponseWriter, req *http.Request) {
+				rw.Write([]byte(req.URL.Path + " " + OriginalPathFromContext(req.Context())))
+			})
+			r.Rewrite("/api/latest/*rest", "/api/v3/*rest")
+			r.Rewrite("/loop", "/loop")
+			h := NewHandler(r)
+
+			So(do(h, "GET", "/api/latest/a").Body.String(), ShouldEqual, "/api/v3/a /api/latest/a")
+			So(do(h, "GET", "/api/v3/a").Body.String(), ShouldEqual, "/api/v3/a ")
+			rec := do(h, "GET", "/loop")
+			So(rec.Code, ShouldEqual, http.StatusInternalServerError)
+			So(rec.Body.String(), ShouldNotContainSubstring, "loop")
+
+			var logged error
+			h = NewHandler(r, WithErrorHandler(func(rw http.ResponseWriter, req *http.Request, err error) {
+				logged = err
+				rw.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			So(do(h, "GET", "/loop").Code, ShouldEqual, http.StatusServiceUnavailable)
+			So(logged, ShouldBeError, "rewrite loop: /loop -> /loop")
+		})
+
+		Convey("explain", func() {
+			h := ExplainHandler(r)
+			rec := do(h, "GET", "/debug?path=/users/1")
+			So(rec.Code, ShouldEqual, http.StatusOK)
+			So(rec.Header().Get("Content-Type"), ShouldEqual, "application/json; charset=utf-8")
+
+			var trace Trace
+			So(json.Unmarshal(rec.Body.Bytes(), &trace), ShouldBeNil)
+			So(trace.Pattern, ShouldEqual, "/users/:id")
+			So(trace.Params["id"], ShouldEqual, "1")
+
+			So(json.Unmarshal(do(h, "GET", "/debug?method=POST&path=/docs").Body.Bytes(), &trace), ShouldBeNil)
+			So(trace.Redirect, ShouldBeTrue)
+
+			So(do(h, "GET", "/debug").Code, ShouldEqual, http.StatusBadRequest)
+		})
+	})
+}
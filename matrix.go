@@ -0,0 +1,55 @@
+package router
+
+import "strings"
+
+// 去掉path中每个路径段的矩阵参数，返回去掉后的路径及各路径段的矩阵参数，path中不存在矩阵参数时原样返回path
+// 路径段中第1个';'之后的部分为以';'分隔的矩阵参数，不包含'='的参数的值为空
+func stripMatrix(path string) (string, [][]UrlParam) {
+	if strings.IndexByte(path, ';') < 0 {
+		return path, nil
+	}
+
+	var matrix [][]UrlParam
+	buf := strings.Builder{}
+	seg := -1 // 当前路径段的序号，path以'/'开头时第1个路径段的序号为0
+	for len(path) > 0 {
+		if path[0] == '/' {
+			buf.WriteByte('/')
+			path = path[1:]
+			seg++
+			continue
+		}
+
+		end := strings.IndexByte(path, '/')
+		if end < 0 {
+			end = len(path)
+		}
+		s := path[:end]
+		path = path[end:]
+
+		i := strings.IndexByte(s, ';')
+		if i < 0 {
+			buf.WriteString(s)
+			continue
+		}
+		buf.WriteString(s[:i])
+
+		if seg < 0 {
+			seg = 0
+		}
+		for len(matrix) <= seg {
+			matrix = append(matrix, nil)
+		}
+		for _, kv := range strings.Split(s[i+1:], ";") {
+			if kv == "" {
+				continue
+			}
+			k, v := kv, ""
+			if j := strings.IndexByte(kv, '='); j >= 0 {
+				k, v = kv[:j], kv[j+1:]
+			}
+			matrix[seg] = append(matrix[seg], UrlParam{Key: []byte(k), Value: []byte(v)})
+		}
+	}
+	return buf.String(), matrix
+}
@@ -0,0 +1,176 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/gogokit/router"
+)
+
+// Location 为操作在规范中的位置
+type Location struct {
+	Method      string
+	Path        string // 规范中的路径模板
+	OperationID string
+}
+
+// String 返回位置对应的JSON Pointer
+func (l Location) String() string {
+	p := strings.ReplaceAll(strings.ReplaceAll(l.Path, "~", "~0"), "/", "~1")
+	return "#/paths/" + p + "/" + strings.ToLower(l.Method)
+}
+
+// LoadError 为注册规范中的操作时发生的错误
+type LoadError struct {
+	Location Location
+	Err      string
+}
+
+func (e *LoadError) Error() string {
+	return e.Location.String() + ": " + e.Err
+}
+
+// LoadReport 为Load的结果
+type LoadReport struct {
+	Registered        []Location // 已注册的操作
+	UnboundOperations []Location // 未在handlers中找到对应handler的操作
+	UnusedHandlers    []string   // 规范中不存在对应operationId的handler，按字典序排列
+}
+
+type spec struct {
+	Paths map[string]map[string]json.RawMessage `json:"paths"`
+}
+
+type specOperation struct {
+	OperationID string          `json:"operationId"`
+	Summary     string          `json:"summary"`
+	Description string          `json:"description"`
+	Tags        []string        `json:"tags"`
+	Deprecated  bool            `json:"deprecated"`
+	Parameters  []specParameter `json:"parameters"`
+}
+
+type specParameter struct {
+	Name     string `json:"name"`
+	In       string `json:"in"`
+	CatchAll bool   `json:"x-catch-all"`
+}
+
+// Load 读取JSON格式的OpenAPI 3规范，将每个操作按照operationId绑定到handlers中的handler并注册到r中
+// 路径模板中的"{name}"转换为":name"，带有"x-catch-all: true"的路径参数转换为"*name"
+// 注册时发生冲突或路径非法时返回*LoadError，此时位于该操作之前的操作已注册到r中
+func Load[H any](r router.TypedRouter[H], src io.Reader, handlers map[string]H) (*LoadReport, error) {
+	var s spec
+	if err := json.NewDecoder(src).Decode(&s); err != nil {
+		return nil, err
+	}
+
+	report := &LoadReport{}
+	used := make(map[string]bool)
+
+	paths := make([]string, 0, len(s.Paths))
+	for p := range s.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	for _, p := range paths {
+		item := s.Paths[p]
+
+		var common []specParameter
+		if raw, ok := item["parameters"]; ok {
+			if err := json.Unmarshal(raw, &common); err != nil {
+				return report, &LoadError{Location: Location{Path: p}, Err: err.Error()}
+			}
+		}
+
+		methods := make([]string, 0, len(item))
+		for m := range item {
+			if isOperationMethod(m) {
+				methods = append(methods, m)
+			}
+		}
+		sort.Strings(methods)
+
+		for _, m := range methods {
+			loc := Location{Method: strings.ToUpper(m), Path: p}
+			var op specOperation
+			if err := json.Unmarshal(item[m], &op); err != nil {
+				return report, &LoadError{Location: loc, Err: err.Error()}
+			}
+			loc.OperationID = op.OperationID
+
+			h, ok := handlers[op.OperationID]
+			if op.OperationID == "" || !ok {
+				report.UnboundOperations = append(report.UnboundOperations, loc)
+				continue
+			}
+			used[op.OperationID] = true
+
+			pattern := toPattern(p, append(common, op.Parameters...))
+			if err := register(r, loc.Method, pattern, h, Operation{
+				OperationID: op.OperationID,
+				Summary:     op.Summary,
+				Description: op.Description,
+				Tags:        op.Tags,
+				Deprecated:  op.Deprecated,
+			}); err != "" {
+				return report, &LoadError{Location: loc, Err: err}
+			}
+			report.Registered = append(report.Registered, loc)
+		}
+	}
+
+	for id := range handlers {
+		if !used[id] {
+			report.UnusedHandlers = append(report.UnusedHandlers, id)
+		}
+	}
+	sort.Strings(report.UnusedHandlers)
+	return report, nil
+}
+
+// 注册路由并将注册时的panic转换为错误信息返回
+func register[H any](r router.TypedRouter[H], method, pattern string, h H, op Operation) (errMsg string) {
+	defer func() {
+		if err := recover(); err != nil {
+			errMsg = fmt.Sprint(err)
+		}
+	}()
+	r.Register(method, pattern, h, WithOperation(op))
+	return ""
+}
+
+// 将OpenAPI路径模板转换为路由的路径模式
+func toPattern(path string, params []specParameter) string {
+	catchAll := make(map[string]bool)
+	for _, p := range params {
+		if p.In == "path" && p.CatchAll {
+			catchAll[p.Name] = true
+		}
+	}
+
+	buf := strings.Builder{}
+	for i := 0; i < len(path); i++ {
+		if path[i] != '{' {
+			buf.WriteByte(path[i])
+			continue
+		}
+		j := strings.IndexByte(path[i:], '}')
+		if j < 0 {
+			buf.WriteString(path[i:])
+			break
+		}
+		name := path[i+1 : i+j]
+		if catchAll[name] {
+			buf.WriteString("*" + name)
+		} else {
+			buf.WriteString(":" + name)
+		}
+		i += j
+	}
+	return buf.String()
+}
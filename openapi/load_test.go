@@ -0,0 +1,69 @@
+package openapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogokit/router"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLoad(t *testing.T) {
+	Convey("Load", t, func() {
+		Convey("bind", func() {
+			src := `{
+  "openapi": "3.0.3",
+  "paths": {
+    "/users/{id}": {
+      "parameters": [{"name": "id", "in": "path", "required": true}],
+      "get": {"operationId": "getUser", "summary": "get user"},
+      "delete": {"operationId": "deleteUser"}
+    },
+    "/files/{path}": {
+      "get": {
+        "operationId": "getFile",
+        "parameters": [{"name": "path", "in": "path", "required": true, "x-catch-all": true}]
+      }
+    }
+  }
+}`
+			r := router.New()
+			report, err := Load(r, strings.NewReader(src), map[string]interface{}{
+				"getUser":   "get_user",
+				"getFile":   "get_file",
+				"listUsers": "list_users",
+			})
+			So(err, ShouldBeNil)
+			So(report.Registered, ShouldResemble, []Location{
+				{Method: "GET", Path: "/files/{path}", OperationID: "getFile"},
+				{Method: "GET", Path: "/users/{id}", OperationID: "getUser"},
+			})
+			So(report.UnboundOperations, ShouldResemble, []Location{
+				{Method: "DELETE", Path: "/users/{id}", OperationID: "deleteUser"},
+			})
+			So(report.UnusedHandlers, ShouldResemble, []string{"listUsers"})
+
+			m := r.Match("GET", "/files/a/b")
+			So(m.Route, ShouldNotBeNil)
+			So(m.Route.Handler, ShouldEqual, "get_file")
+			So(string(m.Params[0].Value), ShouldEqual, "a/b")
+
+			m = r.Match("GET", "/users/1")
+			So(m.Route.Path, ShouldEqual, "/users/:id")
+			So(m.Route.Meta(operationKey{}).(Operation).Summary, ShouldEqual, "get user")
+		})
+
+		Convey("conflict", func() {
+			src := `{
+  "paths": {
+    "/a/{id}": {"get": {"operationId": "a"}},
+    "/a/{name}": {"get": {"operationId": "b"}}
+  }
+}`
+			_, err := Load(router.New(), strings.NewReader(src), map[string]interface{}{"a": 1, "b": 2})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "#/paths/~1a~1{name}/get: '/a/:name' conflict with the registered path '/a/:id'")
+		})
+	})
+}
@@ -0,0 +1,246 @@
+// Package openapi 根据路由表生成OpenAPI 3文档的paths部分
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gogokit/router"
+	"gopkg.in/yaml.v3"
+)
+
+const Version = "3.0.3"
+
+// Info 对应OpenAPI文档中的info对象
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Operation 为用户提供的操作描述，通过WithOperation附加到路由上，生成文档时合并到对应的operation对象中
+type Operation struct {
+	OperationID string
+	Summary     string
+	Description string
+	Tags        []string
+	Deprecated  bool
+	// Params 为路径参数的补充描述，key为参数名，value为该参数的parameter对象中需要覆盖的字段，如"description"、"schema"
+	Params      map[string]map[string]interface{}
+	RequestBody interface{}
+	// Responses key为状态码或"default"，为空时生成一个默认响应
+	Responses map[string]interface{}
+	// Extensions 为需要写入operation对象的其他字段，如"x-internal"
+	Extensions map[string]interface{}
+}
+
+type operationKey struct{}
+
+// WithOperation 将op作为路由元数据附加到路由上
+func WithOperation(op Operation) router.RouteOption {
+	return router.WithMeta(operationKey{}, op)
+}
+
+// Document 为生成的OpenAPI文档，值均为encoding/json解码后的通用类型，便于确定性地输出JSON和YAML
+type Document map[string]interface{}
+
+// Generate 遍历r中的所有路由生成OpenAPI文档，method不是OpenAPI支持的操作类型的路由以及通过Redirect注册的重定向路由会被忽略
+func Generate[H any](r router.TypedRouter[H], info Info) (Document, error) {
+	paths := make(map[string]interface{})
+	err := r.Walk(func(route *router.Route[H]) error {
+		method := strings.ToLower(route.Method)
+		if !isOperationMethod(method) || route.RedirectRule() != nil {
+			return nil
+		}
+
+		p, err := router.ParsePattern(route.Path)
+		if err != nil {
+			return err
+		}
+		op, _ := route.Meta(operationKey{}).(Operation)
+		// OpenAPI不支持可选的路径参数，包含可选部分的路由按照展开后的每个路径分别生成，声明了格式后缀的路由按每个格式分别生成
+		for _, path := range withFormats(p.Expanded, route.Formats()) {
+			tmpl, params := convertPath(path)
+			item, _ := paths[tmpl].(map[string]interface{})
+			if item == nil {
+				item = make(map[string]interface{})
+				paths[tmpl] = item
+			}
+			if _, ok := item[method]; ok {
+				return errors.New("'" + route.Method + " " + route.Path + "' duplicate with another route mapped to the path '" + tmpl + "'")
+			}
+			item[method] = genOperation(op, params)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := map[string]interface{}{
+		"openapi": Version,
+		"info":    info,
+		"paths":   paths,
+	}
+
+	// 统一转换为encoding/json解码后的类型，使用户提供的任意值都能以相同的方式输出
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var ret Document
+	if err := json.Unmarshal(b, &ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// JSON 返回缩进格式的JSON文档，对象的key按字典序排列
+func (d Document) JSON() ([]byte, error) {
+	return json.MarshalIndent(map[string]interface{}(d), "", "  ")
+}
+
+// YAML 返回YAML格式的文档，对象的key按字典序排列
+func (d Document) YAML() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(map[string]interface{}(d)); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// 为paths中的每个路径添加formats中的每个格式后缀，formats为空时返回paths
+// 以格式后缀结尾的路径，如"/reports/:id.json"，其格式即为该后缀，不再添加
+func withFormats(paths, formats []string) []string {
+	if len(formats) == 0 {
+		return paths
+	}
+	var ret []string
+	for _, path := range paths {
+		if len(formats) == 1 && strings.HasSuffix(path, "."+formats[0]) {
+			ret = append(ret, path)
+			continue
+		}
+		for _, f := range formats {
+			ret = append(ret, path+"."+f)
+		}
+	}
+	return ret
+}
+
+type pathParam struct {
+	name      string
+	catchAll  bool
+	anonymous bool
+}
+
+// 将':'和'*'语法的路径转换为OpenAPI的路径模板，":name"和"*name"均转换为"{name}"
+// OpenAPI的路径参数必须带有名称，匿名通配符按照出现的顺序依次命名为"_1"、"_2"等
+func convertPath(path string) (string, []pathParam) {
+	var params []pathParam
+	anonymous := 0
+	buf := strings.Builder{}
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if c != ':' && c != '*' {
+			buf.WriteByte(c)
+			continue
+		}
+
+		j := i + 1
+		for j < len(path) && isParamNameChar(path[j]) {
+			j++
+		}
+		name := path[i+1 : j]
+		if name == "" {
+			anonymous++
+			name = "_" + strconv.Itoa(anonymous)
+			params = append(params, pathParam{name: name, anonymous: true})
+		} else {
+			params = append(params, pathParam{name: name, catchAll: c == '*'})
+		}
+		buf.WriteString("{" + name + "}")
+		i = j - 1
+	}
+	return buf.String(), params
+}
+
+func genOperation(op Operation, params []pathParam) map[string]interface{} {
+	ret := make(map[string]interface{})
+	for k, v := range op.Extensions {
+		ret[k] = v
+	}
+	if op.OperationID != "" {
+		ret["operationId"] = op.OperationID
+	}
+	if op.Summary != "" {
+		ret["summary"] = op.Summary
+	}
+	if op.Description != "" {
+		ret["description"] = op.Description
+	}
+	if len(op.Tags) > 0 {
+		ret["tags"] = op.Tags
+	}
+	if op.Deprecated {
+		ret["deprecated"] = true
+	}
+	if op.RequestBody != nil {
+		ret["requestBody"] = op.RequestBody
+	}
+
+	if len(params) > 0 {
+		ps := make([]interface{}, 0, len(params))
+		for _, p := range params {
+			v := map[string]interface{}{
+				"name":     p.name,
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]interface{}{"type": "string"},
+			}
+			if p.anonymous {
+				v["description"] = "anonymous wildcard, matches a single path segment"
+			}
+			if p.catchAll {
+				// OpenAPI的路径参数不能包含'/'，此处通过扩展字段说明该参数会匹配剩余的全部路径
+				v["description"] = "catch-all parameter, matches the rest of the path including '/'"
+				v["x-catch-all"] = true
+			}
+			for k, vv := range op.Params[p.name] {
+				v[k] = vv
+			}
+			ps = append(ps, v)
+		}
+		ret["parameters"] = ps
+	}
+
+	if len(op.Responses) > 0 {
+		ret["responses"] = op.Responses
+	} else {
+		ret["responses"] = map[string]interface{}{
+			"default": map[string]interface{}{"description": "default response"},
+		}
+	}
+	return ret
+}
+
+// 与router中':'参数名的字符集相同
+func isParamNameChar(c byte) bool {
+	return c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isOperationMethod(method string) bool {
+	switch method {
+	case "get", "put", "post", "delete", "options", "head", "patch", "trace":
+		return true
+	}
+	return false
+}
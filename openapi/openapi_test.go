@@ -0,0 +1,166 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/gogokit/router"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGenerate(t *testing.T) {
+	Convey("Generate", t, func() {
+		r := router.New()
+		r.Register("GET", "/users/:id", "get_user", WithOperation(Operation{
+			OperationID: "getUser",
+			Summary:     "get user by id",
+			Params: map[string]map[string]interface{}{
+				"id": {"schema": map[string]interface{}{"type": "integer"}},
+			},
+			Responses: map[string]interface{}{
+				"200": map[string]interface{}{"description": "ok"},
+			},
+		}))
+		r.Register("DELETE", "/users/:id", "delete_user")
+		r.Register("GET", "/files/*path", "get_file")
+		r.Register("CONNECT", "/tunnel", "tunnel")
+		r.Redirect("GET", "/old/:id", "/users/:id", 301)
+
+		doc, err := Generate(r, Info{Title: "demo", Version: "1.0"})
+		So(err, ShouldBeNil)
+
+		Convey("json", func() {
+			b, err := doc.JSON()
+			So(err, ShouldBeNil)
+			So(string(b), ShouldEqual, `{
+  "info": {
+    "title": "demo",
+    "version": "1.0"
+  },
+  "openapi": "3.0.3",
+  "paths": {
+    "/files/{path}": {
+      "get": {
+        "parameters": [
+          {
+            "description": "catch-all parameter, matches the rest of the path including '/'",
+            "in": "path",
+            "name": "path",
+            "required": true,
+            "schema": {
+              "type": "string"
+            },
+            "x-catch-all": true
+          }
+        ],
+        "responses": {
+          "default": {
+            "description": "default response"
+          }
+        }
+      }
+    },
+    "/users/{id}": {
+      "delete": {
+        "parameters": [
+          {
+            "in": "path",
+            "name": "id",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "responses": {
+          "default": {
+            "description": "default response"
+          }
+        }
+      },
+      "get": {
+        "operationId": "getUser",
+        "parameters": [
+          {
+            "in": "path",
+            "name": "id",
+            "required": true,
+            "schema": {
+              "type": "integer"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "ok"
+          }
+        },
+        "summary": "get user by id"
+      }
+    }
+  }
+}`)
+		})
+
+		Convey("yaml", func() {
+			b, err := doc.YAML()
+			So(err, ShouldBeNil)
+			So(string(b), ShouldEqual, `info:
+  title: demo
+  version: "1.0"
+openapi: 3.0.3
+paths:
+  /files/{path}:
+    get:
+      parameters:
+        - description: catch-all parameter, matches the rest of the path including '/'
+          in: path
+          name: path
+          required: true
+          schema:
+            type: string
+          x-catch-all: true
+      responses:
+        default:
+          description: default response
+  /users/{id}:
+    delete:
+      parameters:
+        - in: path
+          name: id
+          required: true
+          schema:
+            type: string
+      responses:
+        default:
+          description: default response
+    get:
+      operationId: getUser
+      parameters:
+        - in: path
+          name: id
+          required: true
+          schema:
+            type: integer
+      responses:
+        "200":
+          description: ok
+      summary: get user by id
+`)
+		})
+	})
+	Convey("Formats", t, func() {
+		r := router.New(router.WithFormatSuffix("json", "csv"))
+		r.Register("GET", "/reports/:id", "json", router.WithFormats("json"))
+		r.Register("GET", "/reports/:id", "csv", router.WithFormats("csv"))
+		r.Register("GET", "/openapi.json", "openapi")
+
+		doc, err := Generate(r, Info{Title: "demo", Version: "1.0"})
+		So(err, ShouldBeNil)
+		paths := doc["paths"].(map[string]interface{})
+		So(len(paths), ShouldEqual, 3)
+		So(paths["/openapi.json"], ShouldNotBeNil)
+		So(paths["/reports/{id}.json"], ShouldNotBeNil)
+		So(paths["/reports/{id}.csv"], ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,216 @@
+package router
+
+import (
+	"errors"
+	"strings"
+)
+
+// Pattern 为解析后的路由模式
+type Pattern struct {
+	Method string // 模式中的method前缀，不存在时为空
+	Host   string // 模式中的host前缀，不存在时为空
+	Source string // 去掉method和host前缀后的原始路径
+	Path   string // 转换为':'和'*'语法后的路径，可能包含可选部分
+	// Expanded 为展开Path中的可选部分后得到的全部路径，不包含可选部分时仅有Path本身
+	Expanded []string
+}
+
+// ParsePattern 解析路由模式，支持以下两种语法，但同一模式中不能混用：
+//
+//	/users/:id/files/*path
+//	[METHOD ][HOST]/users/{id}/files/{path...}
+//
+// 花括号语法与Go 1.22 net/http.ServeMux的模式相同，其中"{name}"转换为":name"，"{name...}"转换为"*name"，
+// 与ServeMux不同的是"{name...}"之后可以存在静态后缀，路径末尾的"{$}"表示仅匹配以'/'结尾的路径本身，由于本路由器总是精确匹配，"/a/{$}"与"/a/"等价
+// 两种语法都可以包含method和host前缀
+//
+// 路径中可以包含可选部分，注册时展开为多个路径：
+//
+//	/docs/:page?             展开为/docs和/docs/:page，参数为完整路径段时其前面的'/'同样是可选的
+//	/archive(/:year(/:month)) 展开为/archive、/archive/:year和/archive/:year/:month
+//
+// 可选部分中的参数匹配到空值时视为不存在，例如"/docs/:page?"匹配"/docs/"时Match.Params中不包含page
+//
+// 路径中的字符'('、')'和'?'本身需要转义为"\("、"\)"和"\?"
+func ParsePattern(pattern string) (Pattern, error) {
+	var p Pattern
+	if i := strings.IndexAny(pattern, " \t"); i >= 0 {
+		p.Method = pattern[:i]
+		pattern = strings.TrimLeft(pattern[i:], " \t")
+	}
+
+	i := strings.IndexByte(pattern, '/')
+	if i < 0 {
+		return p, errors.New("first char must be '/'")
+	}
+	p.Host = pattern[:i]
+	p.Source = pattern[i:]
+
+	var err error
+	if p.Path, err = convertBraces(p.Source); err != nil {
+		return p, err
+	}
+	if p.Expanded, err = expandOptional(p.Path); err != nil {
+		return p, err
+	}
+	return p, nil
+}
+
+// 将花括号语法的路径转换为':'和'*'语法
+func convertBraces(pattern string) (string, error) {
+	if !strings.ContainsAny(pattern, "{}") {
+		return pattern, nil
+	}
+
+	if strings.ContainsAny(pattern, ":*") {
+		return "", errors.New("the wildcard '*' and ':' should not be used in a pattern with '{}'")
+	}
+
+	buf := strings.Builder{}
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		if c == '}' {
+			return "", errors.New("unmatched '}'")
+		}
+		if c != '{' {
+			buf.WriteByte(c)
+			continue
+		}
+
+		j := strings.IndexByte(pattern[i:], '}')
+		if j < 0 {
+			return "", errors.New("unmatched '{'")
+		}
+		j += i
+		if pattern[i-1] != '/' || (j+1 < len(pattern) && pattern[j+1] != '/') {
+			return "", errors.New("the wildcard '{}' must be a full path segment")
+		}
+
+		name := pattern[i+1 : j]
+		if name != "$" && !isParamName(strings.TrimSuffix(name, "...")) {
+			return "", errors.New("invalid wildcard name '" + name + "'")
+		}
+		last := j+1 == len(pattern)
+		switch {
+		case name == "$":
+			if !last {
+				return "", errors.New("'{$}' must be at the end of the pattern")
+			}
+		case strings.HasSuffix(name, "..."):
+			buf.WriteString("*" + strings.TrimSuffix(name, "..."))
+		default:
+			buf.WriteString(":" + name)
+		}
+		i = j
+	}
+	return buf.String(), nil
+}
+
+// 展开path中的可选部分，返回的路径按照可选部分不存在的优先排列且无重复
+func expandOptional(path string) ([]string, error) {
+	if !strings.ContainsAny(path, "?()") {
+		return []string{path}, nil
+	}
+
+	alts, i, err := expandSeq(path, 0)
+	if err != nil {
+		return nil, err
+	}
+	if i < len(path) {
+		return nil, errors.New("unmatched ')'")
+	}
+
+	ret := make([]string, 0, len(alts))
+	seen := make(map[string]bool, len(alts))
+	for _, v := range alts {
+		if v == "" {
+			v = "/"
+		}
+		if !seen[v] {
+			seen[v] = true
+			ret = append(ret, v)
+		}
+	}
+	return ret, nil
+}
+
+// 展开path[i:]直到遇到未匹配的')'或path结束，返回展开结果及结束位置
+func expandSeq(path string, i int) ([]string, int, error) {
+	alts := []string{""}
+	for i < len(path) {
+		switch c := path[i]; c {
+		case '(':
+			sub, j, err := expandSeq(path, i+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			if j >= len(path) {
+				return nil, 0, errors.New("unmatched '('")
+			}
+			alts = product(alts, append([]string{""}, sub...))
+			i = j + 1
+		case ')':
+			return alts, i, nil
+		case '?':
+			return nil, 0, errors.New("'?' must follow the name of the wildcard ':'")
+		case '\\':
+			// 转义的'('、')'和'?'表示字符本身，其他字符之前的'\'保持不变
+			if i+1 < len(path) && strings.IndexByte("()?", path[i+1]) >= 0 {
+				i++
+			}
+			alts = product(alts, []string{string(path[i])})
+			i++
+		case ':':
+			j := i + 1
+			for j < len(path) && isParamNameChar(path[j]) {
+				j++
+			}
+			param := path[i:j]
+			if j == len(path) || path[j] != '?' {
+				alts = product(alts, []string{param})
+				i = j
+				continue
+			}
+
+			// 参数为完整的路径段时，其前面的'/'同样是可选的
+			segment := i > 0 && path[i-1] == '/' && (j+1 == len(path) || path[j+1] == '/' || path[j+1] == ')')
+			next := make([]string, 0, 2*len(alts))
+			for _, v := range alts {
+				if segment {
+					next = append(next, strings.TrimSuffix(v, "/"))
+				} else {
+					next = append(next, v)
+				}
+			}
+			for _, v := range alts {
+				next = append(next, v+param)
+			}
+			alts = next
+			i = j + 1
+		default:
+			alts = product(alts, []string{string(c)})
+			i++
+		}
+	}
+	return alts, i, nil
+}
+
+// 返回a中每个串与b中每个串拼接的结果
+func product(a, b []string) []string {
+	ret := make([]string, 0, len(a)*len(b))
+	for _, v := range a {
+		for _, vv := range b {
+			ret = append(ret, v+vv)
+		}
+	}
+	return ret
+}
+
+func isParamName(name string) bool {
+	for i := 0; i < len(name); i++ {
+		if !isParamNameChar(name[i]) {
+			return false
+		}
+	}
+	return name != ""
+}
@@ -0,0 +1,134 @@
+package router
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPattern(t *testing.T) {
+	Convey("ParsePattern", t, func() {
+		cases := map[string]Pattern{
+			"/users/:id":                  {Source: "/users/:id", Path: "/users/:id", Expanded: []string{"/users/:id"}},
+			"GET /items/{id}":             {Method: "GET", Source: "/items/{id}", Path: "/items/:id", Expanded: []string{"/items/:id"}},
+			"example.com/files/{path...}": {Host: "example.com", Source: "/files/{path...}", Path: "/files/*path", Expanded: []string{"/files/*path"}},
+			"POST  example.com/items/{$}": {Method: "POST", Host: "example.com", Source: "/items/{$}", Path: "/items/", Expanded: []string{"/items/"}},
+			"/repos/{path...}/-/blob":     {Source: "/repos/{path...}/-/blob", Path: "/repos/*path/-/blob", Expanded: []string{"/repos/*path/-/blob"}},
+			"/v/{major}/{minor}/docs":     {Source: "/v/{major}/{minor}/docs", Path: "/v/:major/:minor/docs", Expanded: []string{"/v/:major/:minor/docs"}},
+		}
+		for pattern, expect := range cases {
+			p, err := ParsePattern(pattern)
+			So(err, ShouldBeNil)
+			So(p, ShouldResemble, expect)
+		}
+
+		errs := map[string]string{
+			"items":             "first char must be '/'",
+			"/items/{id":        "unmatched '{'",
+			"/items/id}":        "unmatched '}'",
+			"/items/a{id}":      "the wildcard '{}' must be a full path segment",
+			"/items/{$}/a":      "'{$}' must be at the end of the pattern",
+			"/items/{id}/:name": "the wildcard '*' and ':' should not be used in a pattern with '{}'",
+		}
+		for pattern, expect := range errs {
+			_, err := ParsePattern(pattern)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, expect)
+		}
+	})
+
+	Convey("Register", t, func() {
+		r := New()
+		r.Register("", "GET /items/{id}", "get_item")
+		r.Register("GET", "/files/{path...}", "get_file")
+		r.Register("GET", "/users/:id", "get_user")
+		r.Register("GET", "example.com/users/{id}", "get_host_user")
+
+		m := r.Match("GET", "/items/1")
+		So(m.Route.Handler, ShouldEqual, "get_item")
+		So(m.Route.Path, ShouldEqual, "/items/{id}")
+		So(ParamValue(m.Params, "id"), ShouldEqual, "1")
+
+		m = r.Match("GET", "/files/a/b")
+		So(ParamValue(m.Params, "path"), ShouldEqual, "a/b")
+
+		So(r.Match("GET", "/users/1").Route.Handler, ShouldEqual, "get_user")
+		So(r.MatchHost("GET", "example.com", "/users/1").Route.Handler, ShouldEqual, "get_host_user")
+		So(r.MatchHost("GET", "other.com", "/users/1").Route.Handler, ShouldEqual, "get_user")
+		So(r.MatchHost("GET", "example.com", "/items/1").Route.Handler, ShouldEqual, "get_item")
+
+		So(func() { r.Register("POST", "GET /a", "a") }, ShouldPanicWith, "method 'POST' conflict with the method 'GET' in the pattern")
+		So(func() { r.Register("", "/a", "a") }, ShouldPanicWith, "method must not be empty")
+		So(func() { r.Register("GET", "/items/{name}", "a") }, ShouldPanicWith, "'/items/:name' conflict with the registered path '/items/:id'")
+	})
+	Convey("Optional", t, func() {
+		cases := map[string][]string{
+			"/docs/:page?":              {"/docs", "/docs/:page"},
+			"/:page?":                   {"/", "/:page"},
+			"/docs/:page?/edit":         {"/docs/edit", "/docs/:page/edit"},
+			"/archive(/:year(/:month))": {"/archive", "/archive/:year", "/archive/:year/:month"},
+			"/v:major?.json":            {"/v.json", "/v:major.json"},
+			"/a(/b)(/c)":                {"/a", "/a/c", "/a/b", "/a/b/c"},
+			`/a\(b\)(/:c\?)`:            {"/a(b)", "/a(b)/:c?"},
+			`/a\b`:                      {`/a\b`},
+		}
+		for pattern, expect := range cases {
+			p, err := ParsePattern(pattern)
+			So(err, ShouldBeNil)
+			So(p.Expanded, ShouldResemble, expect)
+		}
+
+		errs := map[string]string{
+			"/a(/b": "unmatched '('",
+			"/a/b)": "unmatched ')'",
+			"/a/b?": "'?' must follow the name of the wildcard ':'",
+		}
+		for pattern, expect := range errs {
+			_, err := ParsePattern(pattern)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, expect)
+		}
+
+		r := New()
+		r.Register("GET", "/archive(/:year(/:month))", "archive")
+
+		m := r.Match("GET", "/archive")
+		So(m.Route.Handler, ShouldEqual, "archive")
+		So(len(m.Params), ShouldEqual, 0)
+
+		m = r.Match("GET", "/archive/2020/05")
+		So(m.Route.Path, ShouldEqual, "/archive(/:year(/:month))")
+		So(ParamValue(m.Params, "year"), ShouldEqual, "2020")
+		So(ParamValue(m.Params, "month"), ShouldEqual, "05")
+
+		// 可选参数匹配到空值时视为不存在
+		r.Register("GET", "/docs/:page?", "docs")
+		m = r.Match("GET", "/docs/")
+		So(m.Route.Handler, ShouldEqual, "docs")
+		So(hasParam(m.Params, "page"), ShouldBeFalse)
+		m = r.Match("GET", "/archive/2020/")
+		So(ParamValue(m.Params, "year"), ShouldEqual, "2020")
+		So(hasParam(m.Params, "month"), ShouldBeFalse)
+		m = r.Match("GET", "/docs/intro")
+		So(ParamValue(m.Params, "page"), ShouldEqual, "intro")
+
+		var n int
+		r.Walk(func(route *Route[interface{}]) error {
+			n++
+			return nil
+		})
+		So(n, ShouldEqual, 2)
+
+		So(func() { r.Register("GET", "/archive/:y/:m?", "x") }, ShouldPanicWith, "'/archive/:y/:m?' expanded to '/archive/:y': '/archive/:y' conflict with the registered path '/archive/:year/:month'")
+
+		// 任意一个展开后的路径注册失败时，其他路径同样不会注册
+		r.Register("GET", "/news/:id", "news")
+		So(func() { r.Register("GET", "/news(/latest)", "x") }, ShouldPanicWith, "'/news(/latest)' expanded to '/news/latest': '/news/latest' conflict with the registered path '/news/:id'")
+		So(r.Match("GET", "/news").Route, ShouldBeNil)
+		r.Register("GET", "/news", "index")
+		So(r.Match("GET", "/news").Route.Handler, ShouldEqual, "index")
+
+		r.Register("GET", `/files/\(draft\)`, "draft")
+		So(r.Match("GET", "/files/(draft)").Route.Handler, ShouldEqual, "draft")
+	})
+}
@@ -0,0 +1,64 @@
+package router
+
+import "bytes"
+
+// 路由的优先级
+// 树中同一结点的子结点按照首字节排序，查找时不回溯，因此重叠的路由分别注册到不同层的树中：
+// 两个冲突的路由中至少一个通过WithPriority注册时，无论注册的先后顺序，后注册的路由都注册到第1个不存在冲突的层中，
+// 未设置优先级的路由之间冲突时仍然panic。查找时在每层树中分别查找，再按照before定义的顺序从各层的结果中选择，
+// 只有一层树时查找的行为和开销与未使用WithPriority时相同
+
+// 路径段的具体程度，值越大越具体
+const (
+	rankCatchAll    byte = iota // 以'*'通配符开头的路径段
+	rankParam                   // 仅由':'参数或匿名通配符组成的路径段
+	rankConstrained             // 同时包含静态字符和参数的路径段，如":name.:ext"
+	rankStatic                  // 静态路径段
+)
+
+// 返回展开后的路径path中各路径段的具体程度
+func specificity(path string) []byte {
+	var rank []byte
+	for i := 1; i <= len(path); {
+		j := i
+		for j < len(path) && path[j] != '/' {
+			j++
+		}
+		seg := path[i:j]
+		switch {
+		case isCatchAll(seg):
+			rank = append(rank, rankCatchAll)
+		case seg == "*" || len(seg) > 0 && seg[0] == ':' && isParamName(seg[1:]):
+			rank = append(rank, rankParam)
+		case bytesContainsWildcard(seg):
+			rank = append(rank, rankConstrained)
+		default:
+			rank = append(rank, rankStatic)
+		}
+		i = j + 1
+	}
+	return rank
+}
+
+func bytesContainsWildcard(seg string) bool {
+	for i := 0; i < len(seg); i++ {
+		if isWildcard(seg[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// 返回l是否应优先于o：
+// 1：优先级高的路由优先
+// 2：优先级相同时从左向右比较各路径段的具体程度，第1个不同的路径段更具体的路由优先，即静态 > 包含静态字符的参数 > 参数 > '*'通配符
+// 3：仍然相同时先注册的路由优先
+func (l *leaf[H]) before(o *leaf[H]) bool {
+	if l.route.priority != o.route.priority {
+		return l.route.priority > o.route.priority
+	}
+	if c := bytes.Compare(l.rank, o.rank); c != 0 {
+		return c > 0
+	}
+	return l.seq < o.seq
+}
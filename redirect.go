@@ -0,0 +1,159 @@
+package router
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// RedirectRule 为通过Router.Redirect注册的重定向规则
+// To为目标模板，可以是以'/'开头的路径或带有scheme和host的绝对URL，
+// 其中的":name"、"*name"、"{name}"和"{name...}"会被替换为源路由模式中同名参数的值，
+// To中不包含'?'时，请求的查询参数会原样附加到生成的Location中
+type RedirectRule struct {
+	To   string
+	Code int
+}
+
+func (r *trieRouter[H]) Redirect(method, fromPattern, toTemplate string, code int) {
+	method, p := parseRoute(method, fromPattern)
+
+	switch code {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		panic("invalid redirect code " + strconv.Itoa(code))
+	}
+
+	prefix, tmpl := splitTemplate(toTemplate)
+	if prefix == "" && !strings.HasPrefix(tmpl, "/") {
+		panic("the redirect target '" + toTemplate + "' must start with '/' or be an absolute URL")
+	}
+
+	// 目标模板中的参数必须是源路由模式中的参数
+	var names []string
+	for _, path := range p.Expanded {
+		names = append(names, wildcardNames(path)...)
+	}
+	for _, name := range templateParams(tmpl) {
+		if !containsString(names, name) {
+			panic("the param '" + name + "' of the redirect target '" + toTemplate + "' is not defined by '" + p.Source + "'")
+		}
+	}
+
+	var zero H
+	r.register(method, p, zero, []RouteOption{func(o *routeOptions) {
+		o.redirect = &RedirectRule{To: toTemplate, Code: code}
+		o.public = true
+	}})
+}
+
+// Location 使用params替换目标模板中的参数并返回重定向的目标地址，':'参数的值按路径段转义，'*'参数的值按'/'分隔后逐段转义
+// 目标为路径时，开头连续的'/'会合并为一个，避免"*"参数的值以'/'开头时生成指向其他host的"//host/..."
+func (rule *RedirectRule) Location(params []UrlParam, rawQuery string) string {
+	prefix, tmpl := splitTemplate(rule.To)
+	buf := strings.Builder{}
+	buf.WriteString(prefix)
+	fillTemplate(&buf, tmpl, params, true)
+
+	location := buf.String()
+	if prefix == "" {
+		location = cleanLeadingSlashes(location)
+	}
+	if rawQuery != "" && strings.IndexByte(rule.To, '?') < 0 {
+		location += "?" + rawQuery
+	}
+	return location
+}
+
+// 将path开头连续的'/'和'\'合并为一个'/'，浏览器会将以"//"或"/\"开头的Location视为指向其他host的URL
+func cleanLeadingSlashes(path string) string {
+	i := 0
+	for i < len(path) && (path[i] == '/' || path[i] == '\\') {
+		i++
+	}
+	if i <= 1 {
+		return path
+	}
+	return "/" + path[i:]
+}
+
+// 将tmpl中的参数引用替换为params中同名参数的值后写入buf，escape为true时转义参数值
+func fillTemplate(buf *strings.Builder, tmpl string, params []UrlParam, escape bool) {
+	for i := 0; i < len(tmpl); i++ {
+		name, catchAll, end := templateParam(tmpl, i)
+		if end < 0 {
+			buf.WriteByte(tmpl[i])
+			continue
+		}
+		value := ParamValue(params, name)
+		switch {
+		case !escape:
+			buf.WriteString(value)
+		case catchAll:
+			segs := strings.Split(value, "/")
+			for j, seg := range segs {
+				segs[j] = url.PathEscape(seg)
+			}
+			buf.WriteString(strings.Join(segs, "/"))
+		default:
+			buf.WriteString(url.PathEscape(value))
+		}
+		i = end - 1
+	}
+}
+
+// 将目标模板拆分为scheme和host部分以及其后的部分，使scheme之后的':'不会被视为参数
+func splitTemplate(tmpl string) (string, string) {
+	i := strings.Index(tmpl, "://")
+	if i < 0 {
+		return "", tmpl
+	}
+	j := strings.IndexByte(tmpl[i+3:], '/')
+	if j < 0 {
+		return tmpl, ""
+	}
+	return tmpl[:i+3+j], tmpl[i+3+j:]
+}
+
+// 返回tmpl中引用的全部参数名
+func templateParams(tmpl string) []string {
+	var names []string
+	for i := 0; i < len(tmpl); i++ {
+		name, _, end := templateParam(tmpl, i)
+		if end < 0 {
+			continue
+		}
+		names = append(names, name)
+		i = end - 1
+	}
+	return names
+}
+
+// 如果tmpl[i:]以参数引用开头，返回参数名、是否为'*'参数以及参数引用之后的位置，否则返回的位置小于0
+func templateParam(tmpl string, i int) (string, bool, int) {
+	switch tmpl[i] {
+	case ':', '*':
+		j := i + 1
+		for j < len(tmpl) && isParamNameChar(tmpl[j]) {
+			j++
+		}
+		if j == i+1 {
+			return "", false, -1
+		}
+		return tmpl[i+1 : j], tmpl[i] == '*', j
+	case '{':
+		j := strings.IndexByte(tmpl[i:], '}')
+		if j < 0 {
+			return "", false, -1
+		}
+		name := tmpl[i+1 : i+j]
+		catchAll := strings.HasSuffix(name, "...")
+		name = strings.TrimSuffix(name, "...")
+		if !isParamName(name) {
+			return "", false, -1
+		}
+		return name, catchAll, i + j + 1
+	}
+	return "", false, -1
+}
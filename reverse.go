@@ -0,0 +1,176 @@
+package router
+
+import (
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// URLArgs 为根据命名路由生成URL时使用的参数
+type URLArgs struct {
+	Params   map[string]string // 路径参数，'*'通配符的值可以包含'/'
+	Query    url.Values
+	Fragment string
+	// Format 为附加到路径之后的格式后缀（不包含'.'），见WithFormatSuffix，为空时对于通过WithFormats声明了格式的路由使用其第1个格式
+	Format string
+}
+
+func (r *trieRouter[H]) URL(name string, args URLArgs) (*url.URL, error) {
+	route := r.names[name]
+	if route == nil {
+		return nil, errors.New("no route named '" + name + "'")
+	}
+
+	p, err := ParsePattern(route.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(route.formats) > 0 {
+		// 以格式后缀结尾的路由注册时已经去掉了后缀，生成的路径之后会重新添加后缀
+		for i, expanded := range p.Expanded {
+			p.Expanded[i], _ = splitFormat(expanded, r.opts.formats)
+		}
+	}
+
+	var path string
+	var used []string
+	for _, expanded := range p.Expanded {
+		names := wildcardNames(expanded)
+		if containsString(names, "") {
+			return nil, errors.New("the route '" + name + "' with the anonymous wildcard '*' can not be built")
+		}
+		if len(names) >= len(used) && allProvided(names, args.Params) {
+			path, used = expanded, names
+		}
+	}
+	if path == "" {
+		return nil, errors.New("missing params for the route '" + name + "' with the pattern '" + route.Path + "'")
+	}
+	for _, k := range sortedParamKeys(args.Params) {
+		if !containsString(used, k) {
+			return nil, errors.New("the param '" + k + "' is not used by the route '" + name + "' with the pattern '" + route.Path + "'")
+		}
+	}
+
+	unescaped, escaped, err := fillPath(path, args.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	format := args.Format
+	if format == "" && len(route.formats) > 0 {
+		format = route.formats[0]
+	}
+	if format != "" {
+		if !r.opts.formats[format] {
+			return nil, errors.New("the format '" + format + "' is not enabled by WithFormatSuffix")
+		}
+		if len(route.formats) > 0 && !containsString(route.formats, format) {
+			return nil, errors.New("the route '" + name + "' does not handle the format '" + format + "'")
+		}
+		unescaped += "." + format
+		escaped += "." + format
+	}
+
+	u := &url.URL{
+		Host:     route.Host,
+		Path:     unescaped,
+		RawQuery: args.Query.Encode(),
+		Fragment: args.Fragment,
+	}
+	if escaped != u.EscapedPath() {
+		u.RawPath = escaped
+	}
+
+	m := r.MatchHost(route.Method, route.Host, unescaped)
+	if m.Route != route || m.Format != format {
+		return nil, errors.New("the path '" + escaped + "' built for the route '" + name + "' does not match the route")
+	}
+	for _, k := range used {
+		if ParamValue(m.Params, k) != args.Params[k] {
+			return nil, errors.New("the value '" + args.Params[k] + "' of the param '" + k + "' is ambiguous in the path '" + escaped + "'")
+		}
+	}
+	return u, nil
+}
+
+// 使用params中的值替换path中的通配符，返回未转义和转义后的路径
+func fillPath(path string, params map[string]string) (string, string, error) {
+	unescaped := strings.Builder{}
+	escaped := strings.Builder{}
+	for len(path) > 0 {
+		wildcard, idx := findWildcard([]byte(path))
+		if idx < 0 {
+			unescaped.WriteString(path)
+			escaped.WriteString((&url.URL{Path: path}).EscapedPath())
+			break
+		}
+		static := path[:idx]
+		unescaped.WriteString(static)
+		escaped.WriteString((&url.URL{Path: static}).EscapedPath())
+		path = path[idx+len(wildcard):]
+
+		key := string(wildcard[1:])
+		value := params[key]
+		if value == "" {
+			return "", "", errors.New("the value of the param '" + key + "' must not be empty")
+		}
+
+		unescaped.WriteString(value)
+		if wildcard[0] == '*' {
+			segs := strings.Split(value, "/")
+			for i, seg := range segs {
+				segs[i] = url.PathEscape(seg)
+			}
+			escaped.WriteString(strings.Join(segs, "/"))
+			continue
+		}
+		if strings.IndexByte(value, '/') >= 0 {
+			return "", "", errors.New("the value of the param '" + key + "' must not contain '/'")
+		}
+		escaped.WriteString(url.PathEscape(value))
+	}
+	return unescaped.String(), escaped.String(), nil
+}
+
+// 返回path中各个通配符的名称，匿名通配符的名称为空
+func wildcardNames(path string) []string {
+	var names []string
+	for {
+		wildcard, idx := findWildcard([]byte(path))
+		if idx < 0 {
+			return names
+		}
+		names = append(names, string(wildcard[1:]))
+		path = path[idx+len(wildcard):]
+	}
+}
+
+func allProvided(names []string, params map[string]string) bool {
+	for _, name := range names {
+		if _, ok := params[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedParamKeys(params map[string]string) []string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package router
+
+import (
+	"net/url"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestURL(t *testing.T) {
+	Convey("URL", t, func() {
+		r := New()
+		r.Register("GET", "/users/:id", "user", WithName("user"))
+		r.Register("GET", "/files/*path", "file", WithName("file"))
+		r.Register("GET", "/archive(/:year(/:month))", "archive", WithName("archive"))
+		r.Register("GET", "/tarballs/:name.tar.:ext", "tarball", WithName("tarball"))
+		r.Register("GET", "example.com/items/{id}", "item", WithName("item"))
+		r.Register("GET", "/health/*/live", "health", WithName("health"))
+		r.Register("GET", "/range/:from-:to", "range", WithName("range"))
+
+		u, err := r.URL("user", URLArgs{
+			Params:   map[string]string{"id": "a b"},
+			Query:    url.Values{"q": {"x&y"}, "a": {"1"}},
+			Fragment: "top",
+		})
+		So(err, ShouldBeNil)
+		So(u.Path, ShouldEqual, "/users/a b")
+		So(u.RawPath, ShouldBeEmpty)
+		So(u.String(), ShouldEqual, "/users/a%20b?a=1&q=x%26y#top")
+
+		// ';'和','在路径中具有特殊含义，只能通过RawPath保留转义后的形式
+		u, err = r.URL("user", URLArgs{Params: map[string]string{"id": "a;b,c"}})
+		So(err, ShouldBeNil)
+		So(u.Path, ShouldEqual, "/users/a;b,c")
+		So(u.RawPath, ShouldEqual, "/users/a%3Bb%2Cc")
+		So(u.String(), ShouldEqual, "/users/a%3Bb%2Cc")
+
+		u, err = r.URL("file", URLArgs{Params: map[string]string{"path": "a/b?c"}})
+		So(err, ShouldBeNil)
+		So(u.Path, ShouldEqual, "/files/a/b?c")
+		So(u.String(), ShouldEqual, "/files/a/b%3Fc")
+
+		u, err = r.URL("archive", URLArgs{Params: map[string]string{"year": "2020"}})
+		So(err, ShouldBeNil)
+		So(u.String(), ShouldEqual, "/archive/2020")
+		u, err = r.URL("archive", URLArgs{})
+		So(err, ShouldBeNil)
+		So(u.String(), ShouldEqual, "/archive")
+
+		u, err = r.URL("item", URLArgs{Params: map[string]string{"id": "1"}})
+		So(err, ShouldBeNil)
+		So(u.String(), ShouldEqual, "//example.com/items/1")
+
+		errs := []struct {
+			name   string
+			params map[string]string
+			expect string
+		}{
+			{"x", nil, "no route named 'x'"},
+			{"user", nil, "missing params for the route 'user' with the pattern '/users/:id'"},
+			{"archive", map[string]string{"month": "1"}, "the param 'month' is not used by the route 'archive' with the pattern '/archive(/:year(/:month))'"},
+			{"user", map[string]string{"id": "a/b"}, "the value of the param 'id' must not contain '/'"},
+			{"user", map[string]string{"id": ""}, "the value of the param 'id' must not be empty"},
+			{"tarball", map[string]string{"name": "a.b", "ext": "gz"}, "the path '/tarballs/a.b.tar.gz' built for the route 'tarball' does not match the route"},
+			{"range", map[string]string{"from": "a-b", "to": "c"}, "the value 'a-b' of the param 'from' is ambiguous in the path '/range/a-b-c'"},
+			{"health", nil, "the route 'health' with the anonymous wildcard '*' can not be built"},
+		}
+		for _, e := range errs {
+			_, err := r.URL(e.name, URLArgs{Params: e.params})
+			So(err, ShouldBeError, e.expect)
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package router
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// MaxRewriteDepth 为一次查找中最多连续应用的重写规则数
+const MaxRewriteDepth = 8
+
+// rewriteRule 为通过Router.Rewrite注册的重写规则
+type rewriteRule struct {
+	from string
+	to   string
+}
+
+func (r *trieRouter[H]) Rewrite(fromPattern, toTemplate string) {
+	p, err := ParsePattern(fromPattern)
+	if err != nil {
+		panic(err.Error())
+	}
+	if p.Host != "" {
+		panic("the rewrite pattern '" + fromPattern + "' must not contain a host")
+	}
+	if !strings.HasPrefix(toTemplate, "/") || strings.IndexByte(toTemplate, '?') >= 0 {
+		panic("the rewrite target '" + toTemplate + "' must be a path starting with '/'")
+	}
+
+	var names []string
+	for _, path := range p.Expanded {
+		names = append(names, wildcardNames(path)...)
+	}
+	for _, name := range templateParams(toTemplate) {
+		if !containsString(names, name) {
+			panic("the param '" + name + "' of the rewrite target '" + toTemplate + "' is not defined by '" + p.Source + "'")
+		}
+	}
+
+	if r.rewrites == nil {
+		r.rewrites = make(map[string]*node)
+	}
+	root := r.rewrites[p.Method]
+	if root == nil {
+		root = &node{}
+		r.rewrites[p.Method] = root
+	}
+	rule := &rewriteRule{from: p.Source, to: toTemplate}
+	for _, path := range p.Expanded {
+		root.Register([]byte(path), rule)
+	}
+}
+
+// 依次应用与path匹配的重写规则，返回最终用于查找的路径
+// 同一路径再次出现或应用的规则数超过MaxRewriteDepth时返回错误
+func (r *trieRouter[H]) rewrite(method, path string) (string, error) {
+	if len(r.rewrites) == 0 {
+		return path, nil
+	}
+
+	var visited []string // 只有存在匹配的规则时才记录经过的路径
+	for {
+		rule, params := r.findRewrite(method, path)
+		if rule == nil {
+			return path, nil
+		}
+		if visited == nil {
+			visited = append(make([]string, 0, MaxRewriteDepth+2), path)
+		}
+
+		buf := strings.Builder{}
+		fillTemplate(&buf, rule.to, params, false)
+		path = buf.String()
+		if containsString(visited, path) {
+			return "", errors.New("rewrite loop: " + strings.Join(append(visited, path), " -> "))
+		}
+		visited = append(visited, path)
+		if len(visited) > MaxRewriteDepth+1 {
+			return "", errors.New("rewrite depth exceeds " + strconv.Itoa(MaxRewriteDepth) + ": " + strings.Join(visited, " -> "))
+		}
+	}
+}
+
+// 返回与path匹配的重写规则，method对应的规则优先于不带method的规则
+func (r *trieRouter[H]) findRewrite(method, path string) (*rewriteRule, []UrlParam) {
+	if root := r.rewrites[method]; root != nil {
+		if h, p, _ := root.Lookup([]byte(path)); h != nil {
+			return h.(*rewriteRule), p
+		}
+	}
+	if root := r.rewrites[""]; root != nil && method != "" {
+		if h, p, _ := root.Lookup([]byte(path)); h != nil {
+			return h.(*rewriteRule), p
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,146 @@
+package router
+
+// Route 为一条已注册的路由
+type Route[H any] struct {
+	Method  string
+	Host    string // 路由模式中的host前缀，不存在时为空
+	Path    string // 注册时使用的路径模式，不包含method和host前缀
+	Handler H
+	routeOptions
+}
+
+// RouteOption 用于在注册时设置路由的附加属性
+type RouteOption func(o *routeOptions)
+
+// routeOptions 为通过RouteOption设置的路由属性，与handler的类型无关
+type routeOptions struct {
+	name        string
+	meta        map[interface{}]interface{}
+	barePrefix  bool
+	catchAll    string // barePrefix为true时路径末尾的'*'通配符的名称
+	priority    int
+	hasPriority bool
+	scopes      []string
+	public      bool
+	aliases     []string      // 通过Router.Alias注册的别名
+	redirect    *RedirectRule // 通过Router.Redirect注册的路由的重定向规则
+	formats     []string      // 通过WithFormats声明的格式后缀
+}
+
+// WithName 设置路由的名称，同一路由器中的名称必须唯一
+func WithName(name string) RouteOption {
+	if name == "" {
+		panic("route name must not be empty")
+	}
+	return func(o *routeOptions) {
+		o.name = name
+	}
+}
+
+// Name 返回路由的名称，未设置时为空
+func (o *routeOptions) Name() string {
+	return o.name
+}
+
+// WithMeta 为路由附加一项元数据
+// 与context.Context的key类似，key应使用包内未导出的自定义类型以避免不同包之间的冲突
+func WithMeta(key, value interface{}) RouteOption {
+	if key == nil {
+		panic("meta key must not be nil")
+	}
+	return func(o *routeOptions) {
+		if o.meta == nil {
+			o.meta = make(map[interface{}]interface{})
+		}
+		o.meta[key] = value
+	}
+}
+
+// WithBarePrefix 使路径末尾的'*'通配符同样匹配空串及不带'/'的前缀本身
+// 例如"/files/*path"将同时匹配"/files"、"/files/"和"/files/a/b"，前两者的path参数为空串
+func WithBarePrefix() RouteOption {
+	return func(o *routeOptions) {
+		o.barePrefix = true
+	}
+}
+
+// WithPriority 设置路由的优先级并允许路由与已注册的路由重叠
+// 多个路由都能匹配请求时，优先级高的路由优先，优先级相同时按照路径的具体程度及注册顺序选择，默认优先级为0
+func WithPriority(priority int) RouteOption {
+	return func(o *routeOptions) {
+		o.priority = priority
+		o.hasPriority = true
+	}
+}
+
+// Priority 返回路由的优先级
+func (o *routeOptions) Priority() int {
+	return o.priority
+}
+
+// WithScopes 声明访问路由所需的权限范围，Handler会在调用路由的handler之前通过Authorizer检查请求是否具有这些权限
+func WithScopes(scopes ...string) RouteOption {
+	if len(scopes) == 0 {
+		panic("scopes must not be empty")
+	}
+	scopes = append([]string(nil), scopes...)
+	return func(o *routeOptions) {
+		o.scopes = append(o.scopes, scopes...)
+	}
+}
+
+// WithPublic 声明路由无需任何权限即可访问，用于与遗漏了权限声明的路由相区分，见CheckPolicies
+func WithPublic() RouteOption {
+	return func(o *routeOptions) {
+		o.public = true
+	}
+}
+
+// Scopes 返回访问路由所需的权限范围
+func (o *routeOptions) Scopes() []string {
+	return append([]string(nil), o.scopes...)
+}
+
+// Public 返回路由是否通过WithPublic声明为无需权限即可访问
+func (o *routeOptions) Public() bool {
+	return o.public
+}
+
+// Aliases 返回通过Router.Alias为路由注册的别名
+func (o *routeOptions) Aliases() []string {
+	return append([]string(nil), o.aliases...)
+}
+
+// RedirectRule 返回通过Router.Redirect注册的路由的重定向规则，其他路由返回nil
+func (o *routeOptions) RedirectRule() *RedirectRule {
+	return o.redirect
+}
+
+// AliasOption 用于设置别名的属性
+type AliasOption func(a *alias)
+
+type alias struct {
+	pattern    string
+	deprecated bool
+}
+
+// DeprecatedAlias 声明别名已废弃，Handler对命中该别名的请求添加"Deprecation: true"响应头
+func DeprecatedAlias() AliasOption {
+	return func(a *alias) {
+		a.deprecated = true
+	}
+}
+
+// Meta 返回key对应的元数据，不存在时返回nil
+func (o *routeOptions) Meta(key interface{}) interface{} {
+	return o.meta[key]
+}
+
+// Metadata 返回路由全部元数据的拷贝
+func (o *routeOptions) Metadata() map[interface{}]interface{} {
+	ret := make(map[interface{}]interface{}, len(o.meta))
+	for k, v := range o.meta {
+		ret[k] = v
+	}
+	return ret
+}
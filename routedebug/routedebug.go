@@ -0,0 +1,198 @@
+// Package routedebug 提供以JSON和HTML格式展示路由表、树结构以及在线测试查找结果的http.Handler
+//
+// Handler只读取路由器，不注册任何全局路由，可以挂载在任意前缀下并由调用方的鉴权中间件保护，例如：
+//
+//	mux.Handle("/admin/routes/", adminAuth(http.StripPrefix("/admin/routes", routedebug.New(r))))
+package routedebug
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/gogokit/router"
+	"github.com/gogokit/router/routefile"
+)
+
+// Route 为路由表中的一条路由
+type Route struct {
+	Method     string            `json:"method"`
+	Host       string            `json:"host,omitempty"`
+	Pattern    string            `json:"pattern"`
+	Name       string            `json:"name,omitempty"`
+	Aliases    []string          `json:"aliases,omitempty"`
+	Priority   int               `json:"priority,omitempty"`
+	Scopes     []string          `json:"scopes,omitempty"`
+	Public     bool              `json:"public,omitempty"`
+	Redirect   string            `json:"redirect,omitempty"` // 重定向路由的状态码及目标模板，如"301 /new/:id"
+	Meta       map[string]string `json:"meta,omitempty"`
+	Middleware []string          `json:"middleware,omitempty"`
+}
+
+// Table 为JSON格式输出的内容
+type Table struct {
+	Routes []Route       `json:"routes"`
+	Tree   string        `json:"tree"`
+	Lookup *router.Trace `json:"lookup,omitempty"` // 请求中指定了查询参数path时的查找结果
+}
+
+// Handler 展示路由器的路由表，仅支持GET和HEAD请求：
+// 查询参数format=json时输出JSON，否则输出HTML页面；
+// 查询参数path不为空时同时输出对该路径的查找结果，查找的method和host分别由查询参数method和host指定，method默认为GET
+type Handler[H any] struct {
+	router router.TypedRouter[H]
+}
+
+func New[H any](r router.TypedRouter[H]) *Handler[H] {
+	return &Handler[H]{router: r}
+}
+
+// Table 返回当前的路由表，path不为空时同时返回查找结果，见Router.ExplainHost
+func (h *Handler[H]) Table(method, host, path string) (*Table, error) {
+	t := &Table{Routes: []Route{}, Tree: h.router.Dump()}
+	err := h.router.Walk(func(route *router.Route[H]) error {
+		var redirect string
+		if rule := route.RedirectRule(); rule != nil {
+			redirect = strconv.Itoa(rule.Code) + " " + rule.To
+		}
+		t.Routes = append(t.Routes, Route{
+			Method:     route.Method,
+			Host:       route.Host,
+			Pattern:    route.Path,
+			Name:       route.Name(),
+			Aliases:    route.Aliases(),
+			Priority:   route.Priority(),
+			Scopes:     route.Scopes(),
+			Public:     route.Public(),
+			Redirect:   redirect,
+			Meta:       formatMeta(route.Metadata()),
+			Middleware: routefile.MiddlewareNames(route),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if path != "" {
+		if method == "" {
+			method = http.MethodGet
+		}
+		trace := h.router.ExplainHost(method, host, path)
+		t.Lookup = &trace
+	}
+	return t, nil
+}
+
+func (h *Handler[H]) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		rw.Header().Set("Allow", "GET, HEAD")
+		http.Error(rw, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := req.URL.Query()
+	t, err := h.Table(q.Get("method"), q.Get("host"), q.Get("path"))
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// 路由表属于内部信息，禁止缓存及内容类型嗅探
+	rw.Header().Set("Cache-Control", "no-store")
+	rw.Header().Set("X-Content-Type-Options", "nosniff")
+
+	if q.Get("format") == "json" {
+		rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+		enc := json.NewEncoder(rw)
+		enc.SetIndent("", "  ")
+		enc.Encode(t)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+	page.Execute(rw, struct {
+		*Table
+		Method string
+		Host   string
+		Path   string
+	}{
+		Table:  t,
+		Method: q.Get("method"),
+		Host:   q.Get("host"),
+		Path:   q.Get("path"),
+	})
+}
+
+// 将元数据转换为字符串形式，key为字符串类型时使用其值，否则使用其类型名
+func formatMeta(meta map[interface{}]interface{}) map[string]string {
+	if len(meta) == 0 {
+		return nil
+	}
+	ret := make(map[string]string, len(meta))
+	for k, v := range meta {
+		key := fmt.Sprintf("%T", k)
+		if reflect.ValueOf(k).Kind() == reflect.String {
+			key = fmt.Sprint(k)
+		}
+		ret[key] = fmt.Sprint(v)
+	}
+	return ret
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// 页面不引用任何外部资源，表单提交到当前地址，因此可以挂载在任意前缀下
+var page = template.Must(template.New("page").Funcs(template.FuncMap{"sortedKeys": sortedKeys}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Routes</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+pre { background: #f6f6f6; padding: 1em; overflow: auto; }
+</style>
+</head>
+<body>
+<h1>Routes</h1>
+<table>
+<tr><th>Method</th><th>Host</th><th>Pattern</th><th>Aliases</th><th>Name</th><th>Priority</th><th>Scopes</th><th>Redirect</th><th>Meta</th><th>Middleware</th></tr>
+{{range .Routes}}<tr><td>{{.Method}}</td><td>{{.Host}}</td><td>{{.Pattern}}</td><td>{{range .Aliases}}{{.}}<br>{{end}}</td><td>{{.Name}}</td><td>{{.Priority}}</td><td>{{if .Public}}public{{else}}{{range $i, $s := .Scopes}}{{if $i}}, {{end}}{{$s}}{{end}}{{end}}</td><td>{{.Redirect}}</td><td>{{$meta := .Meta}}{{range sortedKeys $meta}}{{.}}={{index $meta .}}<br>{{end}}</td><td>{{range $i, $m := .Middleware}}{{if $i}}, {{end}}{{$m}}{{end}}</td></tr>
+{{end}}</table>
+
+<h2>Lookup</h2>
+<form method="get">
+<input name="method" value="{{if .Method}}{{.Method}}{{else}}GET{{end}}" size="8">
+<input name="host" value="{{.Host}}" size="20" placeholder="host">
+<input name="path" value="{{.Path}}" size="60" placeholder="/path">
+<button type="submit">Lookup</button>
+</form>
+{{with .Lookup}}
+{{if .Pattern}}<p>Matched <code>{{.Pattern}}</code></p>
+{{if .Params}}<table><tr><th>Param</th><th>Value</th></tr>{{$params := .Params}}{{range sortedKeys $params}}<tr><td>{{.}}</td><td>{{index $params .}}</td></tr>{{end}}</table>{{end}}
+{{else}}<p>Not found: {{.Reason}}{{if .Redirect}} (redirect to the path with or without the trailing slash){{end}}</p>
+{{end}}
+<table>
+<tr><th>Host</th><th>Layer</th><th>Node</th><th>Path</th><th>Prefix</th><th>Branch</th></tr>
+{{range .Steps}}<tr><td>{{.Host}}</td><td>{{.Layer}}</td><td>{{.Node}}</td><td>{{.Path}}</td><td>{{.Prefix}}</td><td>{{.Branch}}</td></tr>
+{{end}}</table>
+{{end}}
+
+<h2>Tree</h2>
+<pre>{{.Tree}}</pre>
+</body>
+</html>
+`))
@@ -0,0 +1,71 @@
+package routedebug
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gogokit/router"
+	"github.com/gogokit/router/routefile"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type ownerKey struct{}
+
+func TestHandler(t *testing.T) {
+	Convey("Handler", t, func() {
+		r := router.New()
+		r.Register("GET", "/users/:id", "get_user", router.WithName("user"), router.WithMeta(ownerKey{}, "<team-a>"), router.WithScopes("users:read"))
+		err := routefile.LoadRoutes(r, strings.NewReader(`[{"method": "POST", "path": "/users", "handler": "create", "meta": {"owner": "team-b"}, "middleware": ["auth"]}]`), map[string]interface{}{
+			"create": "create_user",
+			"auth":   func(h interface{}) interface{} { return h },
+		})
+		So(err, ShouldBeNil)
+		r.Alias("user", "/v1/users/:id")
+		r.Redirect("GET", "/old/:id", "/users/:id", http.StatusMovedPermanently)
+
+		h := New(r)
+		do := func(method, target string) *httptest.ResponseRecorder {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+			return rec
+		}
+
+		Convey("json", func() {
+			rec := do("GET", "/?format=json&path=/users/1")
+			So(rec.Code, ShouldEqual, http.StatusOK)
+			So(rec.Header().Get("Cache-Control"), ShouldEqual, "no-store")
+
+			var table Table
+			So(json.Unmarshal(rec.Body.Bytes(), &table), ShouldBeNil)
+			So(table.Routes, ShouldResemble, []Route{
+				{Method: "GET", Pattern: "/old/:id", Public: true, Redirect: "301 /users/:id"},
+				{Method: "GET", Pattern: "/users/:id", Name: "user", Aliases: []string{"/v1/users/:id"}, Scopes: []string{"users:read"}, Meta: map[string]string{"routedebug.ownerKey": "<team-a>"}},
+				{Method: "POST", Pattern: "/users", Meta: map[string]string{"owner": "team-b", "routefile.middlewareKey": "[auth]"}, Middleware: []string{"auth"}},
+			})
+			So(table.Tree, ShouldEqual, r.Dump())
+			So(table.Lookup.Pattern, ShouldEqual, "/users/:id")
+			So(table.Lookup.Params, ShouldResemble, map[string]string{"id": "1"})
+		})
+
+		Convey("html", func() {
+			rec := do("GET", "/?method=POST&path=/nope")
+			So(rec.Code, ShouldEqual, http.StatusOK)
+			So(rec.Header().Get("Content-Type"), ShouldEqual, "text/html; charset=utf-8")
+			body := rec.Body.String()
+			So(body, ShouldContainSubstring, "<td>/users/:id</td><td>/v1/users/:id<br></td><td>user</td><td>0</td><td>users:read</td>")
+			So(body, ShouldContainSubstring, "&lt;team-a&gt;")
+			So(body, ShouldContainSubstring, "Not found: the path diverges from the node &#39;/users&#39; at offset 1")
+			So(body, ShouldContainSubstring, "<pre>GET\n")
+		})
+
+		Convey("method", func() {
+			rec := do("POST", "/")
+			So(rec.Code, ShouldEqual, http.StatusMethodNotAllowed)
+			So(rec.Header().Get("Allow"), ShouldEqual, "GET, HEAD")
+		})
+	})
+}
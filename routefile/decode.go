@@ -0,0 +1,139 @@
+package routefile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"regexp"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+func decodeJSON(data []byte) ([]entry, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, jsonError(data, dec, err)
+	}
+
+	if tok == json.Delim('{') {
+		// 查找routes字段
+		for {
+			if !dec.More() {
+				return nil, nil
+			}
+			key, err := dec.Token()
+			if err != nil {
+				return nil, jsonError(data, dec, err)
+			}
+			if key == "routes" {
+				if tok, err = dec.Token(); err != nil {
+					return nil, jsonError(data, dec, err)
+				}
+				break
+			}
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return nil, jsonError(data, dec, err)
+			}
+		}
+	}
+
+	if tok != json.Delim('[') {
+		return nil, &Error{Line: lineOf(data, int(dec.InputOffset())), Err: "routes must be an array"}
+	}
+
+	var entries []entry
+	for dec.More() {
+		// InputOffset指向上一个token之后，需跳过空白和分隔符才是当前元素的起始位置
+		offset := int(dec.InputOffset())
+		for offset < len(data) && bytes.IndexByte([]byte(" \t\r\n,"), data[offset]) >= 0 {
+			offset++
+		}
+		var e entry
+		if err := dec.Decode(&e); err != nil {
+			return nil, jsonError(data, dec, err)
+		}
+		e.line = lineOf(data, offset)
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func jsonError(data []byte, dec *json.Decoder, err error) error {
+	offset := int(dec.InputOffset())
+	if e, ok := err.(*json.SyntaxError); ok {
+		offset = int(e.Offset)
+	}
+	return &Error{Line: lineOf(data, offset), Err: err.Error()}
+}
+
+func decodeYAML(data []byte) ([]entry, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, &Error{Err: err.Error()}
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	routes := doc.Content[0]
+	if routes.Kind == yaml.MappingNode {
+		var found *yaml.Node
+		for i := 0; i+1 < len(routes.Content); i += 2 {
+			if routes.Content[i].Value == "routes" {
+				found = routes.Content[i+1]
+				break
+			}
+		}
+		if found == nil {
+			return nil, nil
+		}
+		routes = found
+	}
+
+	if routes.Kind != yaml.SequenceNode {
+		return nil, &Error{Line: routes.Line, Err: "routes must be a sequence"}
+	}
+
+	entries := make([]entry, 0, len(routes.Content))
+	for _, v := range routes.Content {
+		var e entry
+		if err := v.Decode(&e); err != nil {
+			return nil, &Error{Line: v.Line, Err: err.Error()}
+		}
+		e.line = v.Line
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+var tomlRoutesHeader = regexp.MustCompile(`^\s*\[\[\s*routes\s*\]\]`)
+
+func decodeTOML(data []byte) ([]entry, error) {
+	var doc struct {
+		Routes []entry `toml:"routes"`
+	}
+	if _, err := toml.Decode(string(data), &doc); err != nil {
+		if e, ok := err.(toml.ParseError); ok {
+			return nil, &Error{Line: e.Position.Line, Err: e.Message}
+		}
+		return nil, &Error{Err: err.Error()}
+	}
+
+	// toml不提供各个表的位置，通过[[routes]]表头所在的行确定每条路由的行号
+	var lines []int
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; s.Scan(); line++ {
+		if tomlRoutesHeader.MatchString(s.Text()) {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == len(doc.Routes) {
+		for i := range doc.Routes {
+			doc.Routes[i].line = lines[i]
+		}
+	}
+	return doc.Routes, nil
+}
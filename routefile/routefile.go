@@ -0,0 +1,222 @@
+// Package routefile 从JSON、YAML或TOML格式的配置文件中加载路由表
+//
+// 配置文件的内容为一个路由列表，JSON和YAML可以直接使用列表或使用包含routes字段的对象，TOML需使用[[routes]]表数组：
+//
+//	routes:
+//	  - method: GET
+//	    path: /users/:id
+//	    handler: getUser
+//	    meta:
+//	      owner: team-a
+//	    middleware: [auth, log]
+package routefile
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gogokit/router"
+)
+
+// Format 为配置文件的格式
+type Format int
+
+const (
+	Unknown Format = iota
+	JSON
+	YAML
+	TOML
+)
+
+// Middleware 对handler进行包装，registry中的中间件必须为该类型或func(H) H
+type Middleware[H any] func(handler H) H
+
+// MetaKey 为配置文件中meta字段的各项在路由元数据中使用的key
+type MetaKey string
+
+type middlewareKey struct{}
+
+// MiddlewareNames 返回通过配置文件为route指定的中间件名称
+func MiddlewareNames[H any](route *router.Route[H]) []string {
+	names, _ := route.Meta(middlewareKey{}).([]string)
+	return names
+}
+
+// Error 为加载某条路由时发生的错误
+type Error struct {
+	File string
+	Line int // 路由在文件中的起始行号，未知时为0
+	Err  string
+}
+
+func (e *Error) Error() string {
+	file := e.File
+	if file == "" {
+		file = "<input>"
+	}
+	if e.Line > 0 {
+		file += ":" + strconv.Itoa(e.Line)
+	}
+	return file + ": " + e.Err
+}
+
+// ErrorList 为加载过程中发生的全部错误
+type ErrorList []*Error
+
+func (l ErrorList) Error() string {
+	msgs := make([]string, 0, len(l))
+	for _, e := range l {
+		msgs = append(msgs, e.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+type entry struct {
+	Method     string                 `json:"method" yaml:"method" toml:"method"`
+	Path       string                 `json:"path" yaml:"path" toml:"path"`
+	Handler    string                 `json:"handler" yaml:"handler" toml:"handler"`
+	Meta       map[string]interface{} `json:"meta" yaml:"meta" toml:"meta"`
+	Middleware []string               `json:"middleware" yaml:"middleware" toml:"middleware"`
+	line       int
+}
+
+// LoadRoutes 读取src中的路由定义，从registry中按名称查找handler和中间件后注册到r中
+// 文件格式根据内容推断：内容为JSON对象或对象数组时为JSON，存在以'['开头的行时为TOML，否则为YAML
+// 路由非法、存在冲突或引用的名称不存在时跳过该路由并继续加载，最终以ErrorList返回全部错误
+func LoadRoutes[H any](r router.TypedRouter[H], src io.Reader, registry map[string]interface{}) error {
+	return load(r, "", Unknown, src, registry)
+}
+
+// LoadFile 与LoadRoutes相同，但从文件中读取路由定义，文件格式根据扩展名确定，错误信息中包含文件名
+func LoadFile[H any](r router.TypedRouter[H], filename string, registry map[string]interface{}) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	format := Unknown
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		format = JSON
+	case ".yaml", ".yml":
+		format = YAML
+	case ".toml":
+		format = TOML
+	}
+	return load(r, filename, format, f, registry)
+}
+
+func load[H any](r router.TypedRouter[H], file string, format Format, src io.Reader, registry map[string]interface{}) error {
+	data, err := ioutil.ReadAll(src)
+	if err != nil {
+		return err
+	}
+	if format == Unknown {
+		format = detect(data)
+	}
+
+	var entries []entry
+	switch format {
+	case JSON:
+		entries, err = decodeJSON(data)
+	case YAML:
+		entries, err = decodeYAML(data)
+	default:
+		entries, err = decodeTOML(data)
+	}
+	if err != nil {
+		if e, ok := err.(*Error); ok {
+			e.File = file
+		}
+		return err
+	}
+
+	var errs ErrorList
+	for _, e := range entries {
+		if msg := register(r, e, registry); msg != "" {
+			errs = append(errs, &Error{File: file, Line: e.line, Err: msg})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// 注册e对应的路由，失败时返回错误信息
+func register[H any](r router.TypedRouter[H], e entry, registry map[string]interface{}) (errMsg string) {
+	v := registry[e.Handler]
+	if v == nil {
+		return "handler '" + e.Handler + "' not found in registry"
+	}
+	h, ok := v.(H)
+	if !ok {
+		return "'" + e.Handler + "' in registry is not a handler"
+	}
+
+	for i := len(e.Middleware) - 1; i >= 0; i-- {
+		var m Middleware[H]
+		switch v := registry[e.Middleware[i]].(type) {
+		case Middleware[H]:
+			m = v
+		case func(H) H:
+			m = v
+		case nil:
+			return "middleware '" + e.Middleware[i] + "' not found in registry"
+		default:
+			return "'" + e.Middleware[i] + "' in registry is not a middleware"
+		}
+		h = m(h)
+	}
+
+	opts := []router.RouteOption{router.WithMeta(middlewareKey{}, e.Middleware)}
+	for k, v := range e.Meta {
+		opts = append(opts, router.WithMeta(MetaKey(k), v))
+	}
+
+	defer func() {
+		if err := recover(); err != nil {
+			errMsg = fmt.Sprint(err)
+		}
+	}()
+	r.Register(e.Method, e.Path, h, opts...)
+	return ""
+}
+
+func detect(data []byte) Format {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		return JSON
+	}
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		// TOML的表头形如[name]或[[name]]，JSON的路由列表中的元素为对象
+		rest := bytes.TrimSpace(trimmed[1:])
+		if len(rest) == 0 || rest[0] == '{' || rest[0] == ']' {
+			return JSON
+		}
+		return TOML
+	}
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		if strings.HasPrefix(strings.TrimSpace(s.Text()), "[") {
+			return TOML
+		}
+	}
+	return YAML
+}
+
+// 返回data中offset处所在的行号，行号从1开始
+func lineOf(data []byte, offset int) int {
+	if offset > len(data) {
+		offset = len(data)
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
@@ -0,0 +1,114 @@
+package routefile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogokit/router"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLoadRoutes(t *testing.T) {
+	registry := map[string]interface{}{
+		"getUser":   "get_user",
+		"listUsers": "list_users",
+		"getFile":   "get_file",
+		"wrap": func(h interface{}) interface{} {
+			return "wrap(" + h.(string) + ")"
+		},
+	}
+
+	Convey("LoadRoutes", t, func() {
+		sources := map[string]string{
+			"json": `{
+  "routes": [
+    {"method": "GET", "path": "/users", "handler": "listUsers"},
+    {
+      "method": "GET",
+      "path": "/users/:id",
+      "handler": "getUser",
+      "meta": {"owner": "team-a"},
+      "middleware": ["wrap"]
+    }
+  ]
+}`,
+			"yaml": `routes:
+  - method: GET
+    path: /users
+    handler: listUsers
+  - method: GET
+    path: /users/:id
+    handler: getUser
+    meta:
+      owner: team-a
+    middleware: [wrap]
+`,
+			"toml": `[[routes]]
+method = "GET"
+path = "/users"
+handler = "listUsers"
+
+[[routes]]
+method = "GET"
+path = "/users/:id"
+handler = "getUser"
+middleware = ["wrap"]
+[routes.meta]
+owner = "team-a"
+`,
+		}
+
+		for _, src := range sources {
+			r := router.New()
+			So(LoadRoutes(r, strings.NewReader(src), registry), ShouldBeNil)
+
+			m := r.Match("GET", "/users/1")
+			So(m.Route, ShouldNotBeNil)
+			So(m.Route.Handler, ShouldEqual, "wrap(get_user)")
+			So(m.Route.Meta(MetaKey("owner")), ShouldEqual, "team-a")
+			So(MiddlewareNames(m.Route), ShouldResemble, []string{"wrap"})
+
+			h, _, _ := r.Lookup("GET", "/users")
+			So(h, ShouldEqual, "list_users")
+		}
+	})
+
+	Convey("errors", t, func() {
+		sources := map[string]string{
+			"json": `[
+  {"method": "GET", "path": "/a/:id", "handler": "getUser"},
+  {"method": "GET", "path": "/a/:name", "handler": "getUser"},
+  {"method": "GET", "path": "b", "handler": "getUser"},
+  {"method": "GET", "path": "/c", "handler": "missing"}
+]`,
+			"yaml": `- method: GET
+  path: /a/:id
+  handler: getUser
+- method: GET
+  path: /a/:name
+  handler: getUser
+- method: GET
+  path: b
+  handler: getUser
+- method: GET
+  path: /c
+  handler: missing
+`,
+		}
+		expect := map[string]string{
+			"json": `<input>:3: '/a/:name' conflict with the registered path '/a/:id'
+<input>:4: first char must be '/'
+<input>:5: handler 'missing' not found in registry`,
+			"yaml": `<input>:4: '/a/:name' conflict with the registered path '/a/:id'
+<input>:7: first char must be '/'
+<input>:10: handler 'missing' not found in registry`,
+		}
+
+		for name, src := range sources {
+			err := LoadRoutes(router.New(), strings.NewReader(src), registry)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, expect[name])
+		}
+	})
+}
@@ -1,8 +1,58 @@
 package router
 
-type Router interface {
-	Register(method, path string, handler interface{})
-	Lookup(method, path string) (handler interface{}, param []UrlParam, redirect bool)
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Router 为handler类型为interface{}的路由器，见New
+type Router = TypedRouter[interface{}]
+
+// TypedRouter 为handler类型为H的路由器，见NewTyped
+type TypedRouter[H any] interface {
+	// Register 注册路由，path的语法见ParsePattern，path包含method前缀时method可以为空
+	Register(method, path string, handler H, opts ...RouteOption)
+	// Redirect 注册一个重定向路由，Handler对命中该路由的请求返回状态码为code的重定向响应，响应的Location由toTemplate生成，见RedirectRule
+	// 重定向路由的handler为H的零值，视为已通过WithPublic声明为公开，可以通过Route.RedirectRule区分
+	Redirect(method, fromPattern, toTemplate string, code int)
+	// Rewrite 注册一个在查找之前应用的重写规则，与fromPattern匹配的请求路径会被替换为由toTemplate生成的路径后再查找
+	// fromPattern的语法见ParsePattern，但不能包含host，不带method前缀时对所有method生效；toTemplate的语法见RedirectRule，但必须为路径
+	// 重写后的路径会继续应用重写规则，至多应用MaxRewriteDepth次，出现循环或超过次数时查找结果的RewriteErr不为nil
+	Rewrite(fromPattern, toTemplate string)
+	// Alias 为名称为name的路由注册一个别名路径模式，命中别名时返回的仍为该路由，反向路由同样使用路由本身的路径模式
+	// 别名的method与路由相同，其中的参数必须是路由中的参数
+	Alias(name, pattern string, opts ...AliasOption)
+	// Lookup 返回method和path对应的handler和参数，未命中时redirect表示存在path添加或删除尾部'/'后的路径对应的路由
+	// 通过Redirect注册的重定向路由没有handler，命中时与未命中相同，需要处理重定向路由时使用Match
+	Lookup(method, path string) (handler H, param []UrlParam, redirect bool)
+	// Match 与Lookup相同，但返回包含命中路由及其元数据的完整结果，不会匹配带有host的路由
+	Match(method, path string) Match[H]
+	// MatchHost 与Match相同，但优先匹配host对应的路由，未命中时再匹配不带host的路由
+	MatchHost(method, host, path string) Match[H]
+	// Candidates 返回能够匹配path的全部路由，第1个即为Match的结果，其余的按照优先级从高到低排列，用于调试重叠的路由
+	Candidates(method, path string) []Candidate[H]
+	// CandidatesHost 与Candidates相同，但同时返回host对应的路由，见MatchHost，host对应的路由排在不带host的路由之前
+	CandidatesHost(method, host, path string) []Candidate[H]
+	// Explain 与Match相同，但返回查找过程中访问的结点、选择的分支以及未命中的原因
+	Explain(method, path string) Trace
+	// ExplainHost 与MatchHost相同，但返回查找过程，未命中host对应的路由时同时包含在不带host的路由中查找的过程
+	ExplainHost(method, host, path string) Trace
+	// Suggest 返回与path最接近的至多n个路由的路径模式，按以路径段为单位的编辑距离排序，参数路径段能匹配任意路径段
+	Suggest(method, path string, n int) []string
+	// SuggestPublic 与Suggest相同，但只返回通过WithPublic声明的路由，用于在未经认证的响应中提示
+	SuggestPublic(method, path string, n int) []string
+	// URL 根据名称为name的路由生成URL，路由带有host时生成的URL中包含host
+	// 对于包含可选部分的路由，使用展开后的路径中包含参数最多且所有参数均已提供的路径，args.Params中存在未使用的参数时返回错误
+	// 参数值按路径段转义，值中包含';'、','等需要转义的字符时同时设置URL的Path和RawPath，
+	// 生成的路径会重新与路由匹配，无法匹配到该路由或参数值不一致时返回错误，例如参数值包含参数之后的分隔符
+	URL(name string, args URLArgs) (*url.URL, error)
+	// Dump 返回各棵树的文本表示，用于调试
+	Dump() string
+	// Walk 按method、host字典序及树中先序依次对每个已注册的路由调用fn，fn返回非nil错误时停止遍历并返回该错误
+	Walk(fn func(route *Route[H]) error) error
 }
 
 type UrlParam struct {
@@ -10,39 +60,632 @@ type UrlParam struct {
 	Value []byte
 }
 
-func New() Router {
-	return &trieRouter{
-		trees: make(map[string]*node, 5),
+// Match 为一次查找的结果
+type Match[H any] struct {
+	Route    *Route[H] // 命中的路由，未命中时为nil
+	Params   []UrlParam
+	Redirect bool // 未命中时表示存在path添加或删除尾部'/'后的路径对应的路由，path可以包含WithMatrixParams的矩阵参数
+	// Alias 为命中的别名的路径模式，通过路由本身的路径模式命中时为空
+	Alias      string
+	Deprecated bool // 命中的别名是否通过DeprecatedAlias声明为已废弃
+	// Path 为用于查找的路径，请求路径经过重写时为重写后的路径，开启WithMatrixParams时不包含矩阵参数
+	Path string
+	// OriginalPath 为重写前的请求路径，未经过重写时为空
+	OriginalPath string
+	// Format 为开启WithFormatSuffix时从请求路径中分离出的格式后缀（不包含'.'），未分离时为空
+	Format string
+	// Matrix 为开启WithMatrixParams时请求路径中各个路径段的矩阵参数，Matrix[i]对应第i个路径段（从0开始），
+	// 不包含矩阵参数的路径段对应的元素为nil，Matrix的长度为最后一个包含矩阵参数的路径段的序号加1
+	Matrix [][]UrlParam
+	// RewriteErr 为应用重写规则时发生的错误，如重写规则形成循环，此时Route为nil
+	RewriteErr error
+
+	leaf *leaf[H] // 命中的结点handler
+}
+
+// Candidate 为能够匹配请求路径的一个路由
+type Candidate[H any] struct {
+	Route  *Route[H]
+	Path   string // 匹配请求的路径，对于包含可选部分的路由为展开后的路径
+	Params []UrlParam
+	Format string // 匹配前从请求路径中分离出的格式后缀，见Match.Format
+}
+
+// New 返回handler类型为interface{}的路由器
+func New(opts ...RouterOption) Router {
+	return NewTyped[interface{}](opts...)
+}
+
+// NewTyped 返回handler类型为H的路由器
+func NewTyped[H any](opts ...RouterOption) TypedRouter[H] {
+	r := &trieRouter[H]{
+		trees: make(map[treeKey][]*node, 5),
+		paths: make(map[treeKey]map[string]*leaf[H], 5),
+		names: make(map[string]*Route[H]),
+	}
+	for _, opt := range opts {
+		opt(&r.opts)
 	}
+	return r
+}
+
+// RouterOption 用于设置路由器的行为
+type RouterOption func(o *routerOptions)
+
+type routerOptions struct {
+	matrixParams bool
+	formats      map[string]bool // 通过WithFormatSuffix开启的格式后缀，未开启时为nil
+}
+
+// WithMatrixParams 使查找时先去掉每个路径段中";key=value"形式的矩阵参数再匹配，去掉的参数通过Match.Matrix按路径段返回
+// 例如"/cars;color=red/models;year=2020"将匹配"/cars/models"
+// 查找使用解码后的路径，因此路径段中转义后的';'同样会被视为矩阵参数的分隔符
+func WithMatrixParams() RouterOption {
+	return func(o *routerOptions) {
+		o.matrixParams = true
+	}
+}
+
+type treeKey struct {
+	method string
+	host   string
 }
 
 // trieRouter 通过预先配置的路由将请求分发到不同的处理程序
-type trieRouter struct {
-	trees map[string]*node // key为http method
+// 每个method和host对应多层树，同一层树中的路径互不冲突，两个冲突的路由中至少一个设置了优先级时后注册的路由注册到后续的层中，
+// 查找时在各层中分别查找并按照路由的优先级选择结果，只有一层时与单棵树的查找相同
+type trieRouter[H any] struct {
+	trees map[treeKey][]*node             // 树中结点的handler均为*leaf[H]
+	paths map[treeKey]map[string]*leaf[H] // 已注册的路径对应的结点handler，用于检查重复注册
+	names map[string]*Route[H]            // 通过WithName设置了名称的路由
+	// rewrites 为各个method对应的重写规则树，key为空串的树中为对所有method生效的规则，结点的handler均为*rewriteRule
+	rewrites map[string]*node
+	opts     routerOptions
+	seq      int // 已注册的路由数
 }
 
-func (r *trieRouter) Register(method, path string, handler interface{}) {
+// leaf 为树中结点存放的handler
+type leaf[H any] struct {
+	route *Route[H]
+	alias *alias // 通过别名注册时不为nil
+	path  string // 展开可选部分后的路径
+	rank  []byte // path的具体程度，见specificity
+	seq   int    // 路由的注册序号
+	// optional 为路由模式中可选部分内的参数，即未出现在所有展开后的路径中的参数，匹配到空值时视为不存在
+	optional []string
+	// variants 为开启WithFormatSuffix时与该结点路径相同、但通过WithFormats声明了不同格式的路由对应的handler
+	variants []*leaf[H]
+}
+
+func (r *trieRouter[H]) Register(method, path string, handler H, opts ...RouteOption) {
+	method, p := parseRoute(method, path)
+	if isNil(handler) {
+		panic("handler must not be nil")
+	}
+
+	r.register(method, p, handler, opts)
+}
+
+// 解析路由模式path并返回路由的method，path包含method前缀时method可以为空
+func parseRoute(method, path string) (string, Pattern) {
+	p, err := ParsePattern(path)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	if method == "" {
+		method = p.Method
+	} else if p.Method != "" && p.Method != method {
+		panic("method '" + method + "' conflict with the method '" + p.Method + "' in the pattern")
+	}
+
 	if method == "" {
 		panic("method must not be empty")
 	}
+	return method, p
+}
 
-	if handler == nil {
-		panic("handler must not be nil")
+// 注册已解析的路由模式p，调用方需保证method不为空
+func (r *trieRouter[H]) register(method string, p Pattern, handler H, opts []RouteOption) *Route[H] {
+	route := &Route[H]{
+		Method:  method,
+		Host:    p.Host,
+		Path:    p.Source,
+		Handler: handler,
+	}
+	for _, opt := range opts {
+		opt(&route.routeOptions)
+	}
+
+	if route.public && len(route.scopes) > 0 {
+		panic("'" + p.Source + "' declared as public must not require scopes")
+	}
+
+	if route.barePrefix {
+		p.Expanded, route.catchAll = addBarePrefix(p.Expanded)
+		if route.catchAll == "" {
+			panic("'" + p.Source + "' must end with the wildcard '*' to match the bare prefix")
+		}
 	}
 
-	root := r.trees[method]
-	if root == nil {
-		root = &node{}
-		r.trees[method] = root
+	if r.opts.formats != nil {
+		p.Expanded = literalFormat(p, route, r.opts.formats)
 	}
 
-	root.Register([]byte(path), handler)
+	if old := r.names[route.name]; old != nil {
+		panic("the route name '" + route.name + "' has been used by '" + old.Method + " " + old.Path + "'")
+	}
+
+	r.insert(treeKey{method: method, host: p.Host}, p, route, nil)
+	if route.name != "" {
+		r.names[route.name] = route
+	}
+	return route
+}
+
+func (r *trieRouter[H]) Alias(name, pattern string, opts ...AliasOption) {
+	route := r.names[name]
+	if route == nil {
+		panic("no route named '" + name + "'")
+	}
+
+	p, err := ParsePattern(pattern)
+	if err != nil {
+		panic(err.Error())
+	}
+	if p.Method != "" && p.Method != route.Method {
+		panic("method '" + p.Method + "' of the alias conflict with the method '" + route.Method + "' of the route '" + name + "'")
+	}
+
+	// 别名中的参数必须是路由中的参数，使handler总能以相同的名称获取参数
+	primary, _ := ParsePattern(route.Path)
+	var names []string
+	for _, path := range primary.Expanded {
+		names = append(names, wildcardNames(path)...)
+	}
+	for _, path := range p.Expanded {
+		for _, n := range wildcardNames(path) {
+			if n != "" && !containsString(names, n) {
+				panic("the param '" + n + "' of the alias '" + p.Source + "' is not defined by the route '" + name + "'")
+			}
+		}
+	}
+
+	a := &alias{pattern: p.Source}
+	for _, opt := range opts {
+		opt(a)
+	}
+	r.insert(treeKey{method: route.Method, host: p.Host}, p, route, a)
+	route.aliases = append(route.aliases, p.Source)
+}
+
+// 将route展开后的各个路径注册到key对应的树中，a不为nil时注册的是route的别名
+func (r *trieRouter[H]) insert(key treeKey, p Pattern, route *Route[H], a *alias) {
+	r.verifyFormats(p.Source, p.Expanded, route)
+	if r.paths[key] == nil {
+		r.paths[key] = make(map[string]*leaf[H])
+	}
+	r.seq++
+	optional := optionalParams(p.Expanded)
+	leaves := make([]*leaf[H], 0, len(p.Expanded))
+	for _, path := range p.Expanded {
+		leaves = append(leaves, &leaf[H]{
+			route:    route,
+			alias:    a,
+			path:     path,
+			rank:     specificity(path),
+			seq:      r.seq,
+			optional: optional,
+		})
+	}
+
+	if len(leaves) > 1 {
+		// 先将全部展开后的路径注册到树的副本中，任意一个路径注册失败时不修改路由器，避免只注册了部分路径
+		tmp := &trieRouter[H]{
+			trees: map[treeKey][]*node{key: cloneTrees(r.trees[key])},
+			paths: map[treeKey]map[string]*leaf[H]{key: make(map[string]*leaf[H], len(r.paths[key]))},
+		}
+		for path, l := range r.paths[key] {
+			c := *l
+			tmp.paths[key][path] = &c
+		}
+		for _, l := range leaves {
+			tmp.registerExpanded(key, p, l)
+		}
+	}
+
+	for _, l := range leaves {
+		r.registerExpanded(key, p, l)
+	}
+}
+
+// 返回trees的副本，副本与trees共享结点的路径及handler
+func cloneTrees(trees []*node) []*node {
+	ret := make([]*node, len(trees))
+	for i, root := range trees {
+		ret[i] = root.clone()
+	}
+	return ret
+}
+
+// 将可选部分展开后的路径注册到key对应的树中，注册失败时的panic信息中包含原始的路由模式
+func (r *trieRouter[H]) registerExpanded(key treeKey, p Pattern, l *leaf[H]) {
+	if len(p.Expanded) > 1 {
+		defer func() {
+			if err := recover(); err != nil {
+				panic("'" + p.Source + "' expanded to '" + l.path + "': " + fmt.Sprint(err))
+			}
+		}()
+	}
+
+	if old := r.paths[key][l.path]; old != nil {
+		// 开启WithFormatSuffix时，路径相同但通过WithFormats声明了不同格式的路由共享树中的同一个结点
+		old.addVariant(l)
+		return
+	}
+
+	trees := r.trees[key]
+	if len(trees) == 0 {
+		trees = append(trees, &node{})
+		r.trees[key] = trees
+	}
+	if msg := tryRegister(trees[0], l.path, l); msg != "" {
+		if !l.route.hasPriority {
+			// 未设置优先级的路由之间不能冲突，只与设置了优先级的路由冲突时与注册的先后顺序无关，同样注册到后续的层中
+			if !r.hasPriority(key) {
+				panic(msg)
+			}
+			r.plainTree(key).Register([]byte(l.path), l)
+		}
+
+		// 注册到第1个不存在冲突的层中
+		registered := false
+		for _, root := range trees[1:] {
+			if tryRegister(root, l.path, l) == "" {
+				registered = true
+				break
+			}
+		}
+		if !registered {
+			root := &node{}
+			root.Register([]byte(l.path), l)
+			r.trees[key] = append(trees, root)
+		}
+	}
+	r.paths[key][l.path] = l
+}
+
+// 返回key对应的树中是否存在设置了优先级的路由
+func (r *trieRouter[H]) hasPriority(key treeKey) bool {
+	for _, l := range r.paths[key] {
+		for _, v := range append([]*leaf[H]{l}, l.variants...) {
+			if v.route.hasPriority {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// 返回由key对应的树中未设置优先级的路由组成的树，用于检查这些路由之间的冲突
+func (r *trieRouter[H]) plainTree(key treeKey) *node {
+	paths := make([]string, 0, len(r.paths[key]))
+	for path, l := range r.paths[key] {
+		for _, v := range append([]*leaf[H]{l}, l.variants...) {
+			if !v.route.hasPriority {
+				paths = append(paths, path)
+				break
+			}
+		}
+	}
+	// 按照路径排序使冲突信息中的已注册路径确定
+	sort.Strings(paths)
+	root := &node{}
+	for _, path := range paths {
+		root.Register([]byte(path), r.paths[key][path])
+	}
+	return root
+}
+
+// 对paths中以"/*name"结尾的路径，在其后添加去掉"/*name"后的路径，返回添加后的路径及通配符名称
+// "/*name"的前缀为空串，而"/"本身已经由通配符匹配，因此不添加路径
+func addBarePrefix(paths []string) ([]string, string) {
+	var ret []string
+	var name string
+	for _, path := range paths {
+		ret = append(ret, path)
+		i := strings.LastIndex(path, "/*")
+		if i < 0 || !isParamName(path[i+2:]) {
+			continue
+		}
+		name = path[i+2:]
+		if i > 0 {
+			ret = append(ret, path[:i])
+		}
+	}
+	return ret, name
 }
 
 // 返回method和path对应的handler和参数，如果未找到则在最后一个参数为true时表示存在path添加或删除尾部'/'后的路径对应的handler
-func (r *trieRouter) Lookup(method, path string) (interface{}, []UrlParam, bool) {
-	if root := r.trees[method]; root != nil {
-		return root.Lookup([]byte(path))
+func (r *trieRouter[H]) Lookup(method, path string) (H, []UrlParam, bool) {
+	m := r.Match(method, path)
+	if m.Route == nil || m.Route.redirect != nil {
+		var zero H
+		return zero, nil, m.Redirect
+	}
+	return m.Route.Handler, m.Params, false
+}
+
+func (r *trieRouter[H]) Match(method, path string) Match[H] {
+	return r.MatchHost(method, "", path)
+}
+
+func (r *trieRouter[H]) MatchHost(method, host, path string) Match[H] {
+	stripped, matrix := path, [][]UrlParam(nil)
+	if r.opts.matrixParams {
+		stripped, matrix = stripMatrix(path)
+	}
+	target, err := r.rewrite(method, stripped)
+	if err != nil {
+		return Match[H]{Path: stripped, Matrix: matrix, RewriteErr: err}
+	}
+
+	m := r.matchHost(method, host, target)
+	m.Path, m.Matrix = target, matrix
+	if target != stripped {
+		m.OriginalPath = path
+		// 重写对客户端不可见，因此不能将客户端重定向到重写后的路径添加或删除尾部'/'后的路径
+		// 只去掉了矩阵参数时仍然可以重定向，此时应当对包含矩阵参数的请求路径添加或删除尾部'/'
+		m.Redirect = false
+	}
+	return m
+}
+
+func (r *trieRouter[H]) matchHost(method, host, path string) Match[H] {
+	// 开启WithFormatSuffix时优先去掉格式后缀后查找，未命中时再查找完整的路径
+	if base, format := splitFormat(path, r.opts.formats); format != "" {
+		if m := r.matchHostFormat(method, host, base, format); m.Route != nil && m.leaf.acceptsSuffix() {
+			m.Format = format
+			return m
+		}
+	}
+	return r.matchHostFormat(method, host, path, "")
+}
+
+func (r *trieRouter[H]) matchHostFormat(method, host, path, format string) Match[H] {
+	if host == "" {
+		return r.match(treeKey{method: method}, path, format)
+	}
+
+	m := r.match(treeKey{method: method, host: host}, path, format)
+	if m.Route != nil {
+		return m
+	}
+	if mm := r.match(treeKey{method: method}, path, format); mm.Route != nil || mm.Redirect {
+		return mm
+	}
+	return m
+}
+
+// 在key对应的树中查找path，format为从路径中分离出的格式后缀，见WithFormatSuffix
+func (r *trieRouter[H]) match(key treeKey, path, format string) Match[H] {
+	trees := r.trees[key]
+	if len(trees) == 0 {
+		return Match[H]{}
+	}
+
+	if len(trees) == 1 {
+		h, p, redirect := trees[0].Lookup([]byte(path))
+		if h == nil {
+			return Match[H]{Redirect: redirect}
+		}
+		l := h.(*leaf[H]).resolve(format)
+		if l == nil {
+			return Match[H]{}
+		}
+		return newMatch(l, p)
+	}
+
+	var best *leaf[H]
+	var bestParams []UrlParam
+	redirect := false
+	for _, root := range trees {
+		h, p, tsr := root.Lookup([]byte(path))
+		if h == nil {
+			redirect = redirect || tsr
+			continue
+		}
+		l := h.(*leaf[H]).resolve(format)
+		if l != nil && (best == nil || l.before(best)) {
+			best, bestParams = l, p
+		}
+	}
+	if best == nil {
+		return Match[H]{Redirect: redirect}
+	}
+	return newMatch(best, bestParams)
+}
+
+func newMatch[H any](l *leaf[H], p []UrlParam) Match[H] {
+	route := l.route
+	p = dropAbsent(p, l.optional)
+	if route.catchAll != "" && !hasParam(p, route.catchAll) {
+		// 匹配到不带'/'的前缀本身时，通配符参数的值为空
+		p = append(p, UrlParam{Key: []byte(route.catchAll), Value: []byte{}})
+	}
+	m := Match[H]{Route: route, Params: p, leaf: l}
+	if l.alias != nil {
+		m.Alias = l.alias.pattern
+		m.Deprecated = l.alias.deprecated
+	}
+	return m
+}
+
+func (r *trieRouter[H]) Candidates(method, path string) []Candidate[H] {
+	return r.CandidatesHost(method, "", path)
+}
+
+func (r *trieRouter[H]) CandidatesHost(method, host, path string) []Candidate[H] {
+	if r.opts.matrixParams {
+		path, _ = stripMatrix(path)
+	}
+	path, err := r.rewrite(method, path)
+	if err != nil {
+		return nil
+	}
+
+	m := r.matchHost(method, host, path)
+	var ret []Candidate[H]
+	seen := make(map[*leaf[H]]bool)
+	if m.leaf != nil {
+		ret = append(ret, Candidate[H]{Route: m.Route, Path: m.leaf.path, Params: m.Params, Format: m.Format})
+		seen[m.leaf] = true
+	}
+
+	// 与MatchHost相同，开启WithFormatSuffix时先列出去掉格式后缀后能够匹配的路由，host对应的路由排在不带host的路由之前
+	type attempt struct {
+		path, format string
+	}
+	attempts := []attempt{{path: path}}
+	if base, format := splitFormat(path, r.opts.formats); format != "" {
+		attempts = []attempt{{base, format}, {path, ""}}
+	}
+	keys := []treeKey{{method: method}}
+	if host != "" {
+		keys = []treeKey{{method: method, host: host}, {method: method}}
+	}
+	for _, a := range attempts {
+		for _, key := range keys {
+			var leaves []*leaf[H]
+			var params [][]UrlParam
+			for _, root := range r.trees[key] {
+				root.lookupAll([]byte(a.path), nil, func(h interface{}, p []UrlParam) {
+					for _, l := range h.(*leaf[H]).handles(a.format) {
+						if !seen[l] {
+							seen[l] = true
+							leaves = append(leaves, l)
+							params = append(params, p)
+						}
+					}
+				})
+			}
+
+			idx := make([]int, len(leaves))
+			for i := range idx {
+				idx[i] = i
+			}
+			sort.SliceStable(idx, func(i, j int) bool {
+				return leaves[idx[i]].before(leaves[idx[j]])
+			})
+			for _, i := range idx {
+				mm := newMatch(leaves[i], params[i])
+				ret = append(ret, Candidate[H]{
+					Route:  mm.Route,
+					Path:   leaves[i].path,
+					Params: mm.Params,
+					Format: a.format,
+				})
+			}
+		}
+	}
+	return ret
+}
+
+func (r *trieRouter[H]) Walk(fn func(route *Route[H]) error) error {
+	keys := make([]treeKey, 0, len(r.trees))
+	for key := range r.trees {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].method != keys[j].method {
+			return keys[i].method < keys[j].method
+		}
+		return keys[i].host < keys[j].host
+	})
+
+	seen := make(map[*Route[H]]bool) // 包含可选部分的路由会在树中出现多次
+	for _, key := range keys {
+		for _, root := range r.trees[key] {
+			err := root.walk(func(h interface{}) error {
+				l := h.(*leaf[H])
+				for _, v := range append([]*leaf[H]{l}, l.variants...) {
+					if seen[v.route] {
+						continue
+					}
+					seen[v.route] = true
+					if err := fn(v.route); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// 返回展开后的路径paths中未出现在所有路径中的参数名
+func optionalParams(paths []string) []string {
+	if len(paths) < 2 {
+		return nil
+	}
+	count := make(map[string]int)
+	var names []string
+	for _, path := range paths {
+		for _, name := range wildcardNames(path) {
+			if name == "" {
+				continue
+			}
+			if count[name] == 0 {
+				names = append(names, name)
+			}
+			count[name]++
+		}
+	}
+	var ret []string
+	for _, name := range names {
+		if count[name] < len(paths) {
+			ret = append(ret, name)
+		}
+	}
+	return ret
+}
+
+// 返回去掉params中值为空的可选参数后的参数，如"/docs/:page?"匹配"/docs/"时page不存在而不是空串
+func dropAbsent(params []UrlParam, optional []string) []UrlParam {
+	if len(optional) == 0 {
+		return params
+	}
+	for i, p := range params {
+		if len(p.Value) > 0 || !containsString(optional, string(p.Key)) {
+			continue
+		}
+		ret := append(params[:i:i], params[i+1:]...)
+		return dropAbsent(ret, optional)
+	}
+	return params
+}
+
+func hasParam(params []UrlParam, key string) bool {
+	for _, p := range params {
+		if string(p.Key) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// 返回h是否为nil，H为接口、指针、函数等类型时其nil值同样视为nil
+func isNil(h interface{}) bool {
+	if h == nil {
+		return true
+	}
+	switch v := reflect.ValueOf(h); v.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+		return v.IsNil()
 	}
-	return nil, nil, false
+	return false
 }
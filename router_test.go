@@ -0,0 +1,517 @@
+package router
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type ownerKey struct{}
+
+type scopeKey struct{}
+
+func TestRouter(t *testing.T) {
+	Convey("Metadata", t, func() {
+		r := New()
+		r.Register("GET", "/users/:id", "get_user", WithMeta(ownerKey{}, "team-a"), WithMeta(scopeKey{}, []string{"user:read"}))
+		r.Register("POST", "/users", "create_user")
+
+		m := r.Match("GET", "/users/1")
+		So(m.Route, ShouldNotBeNil)
+		So(m.Route.Handler, ShouldEqual, "get_user")
+		So(m.Route.Path, ShouldEqual, "/users/:id")
+		So(m.Route.Meta(ownerKey{}), ShouldEqual, "team-a")
+		So(m.Route.Meta(scopeKey{}), ShouldResemble, []string{"user:read"})
+		So(len(m.Params), ShouldEqual, 1)
+
+		h, _, _ := r.Lookup("POST", "/users")
+		So(h, ShouldEqual, "create_user")
+
+		var paths []string
+		err := r.Walk(func(route *Route[interface{}]) error {
+			paths = append(paths, route.Method+" "+route.Path)
+			if route.Path == "/users/:id" {
+				So(route.Meta(ownerKey{}), ShouldEqual, "team-a")
+			} else {
+				So(route.Meta(ownerKey{}), ShouldBeNil)
+			}
+			return nil
+		})
+		So(err, ShouldBeNil)
+		So(paths, ShouldResemble, []string{"GET /users/:id", "POST /users"})
+	})
+	Convey("Typed", t, func() {
+		type handlerFunc func() string
+
+		r := NewTyped[handlerFunc]()
+		r.Register("GET", "/users/:id", func() string { return "get_user" })
+
+		h, param, redirect := r.Lookup("GET", "/users/1")
+		So(h(), ShouldEqual, "get_user")
+		So(string(param[0].Value), ShouldEqual, "1")
+		So(redirect, ShouldBeFalse)
+
+		h, _, redirect = r.Lookup("GET", "/users/1/")
+		So(h, ShouldBeNil)
+		So(redirect, ShouldBeTrue)
+
+		So(func() { r.Register("GET", "/a", nil) }, ShouldPanicWith, "handler must not be nil")
+	})
+	Convey("BarePrefix", t, func() {
+		r := New()
+		r.Register("GET", "/files/*path", "files", WithBarePrefix())
+		r.Register("GET", "/strict/*path", "strict")
+
+		for path, expect := range map[string]string{
+			"/files":     "",
+			"/files/":    "",
+			"/files/a/b": "a/b",
+		} {
+			m := r.Match("GET", path)
+			So(m.Route, ShouldNotBeNil)
+			So(m.Route.Handler, ShouldEqual, "files")
+			So(len(m.Params), ShouldEqual, 1)
+			So(ParamValue(m.Params, "path"), ShouldEqual, expect)
+		}
+
+		m := r.Match("GET", "/strict")
+		So(m.Route, ShouldBeNil)
+		So(m.Redirect, ShouldBeTrue)
+
+		So(func() { r.Register("GET", "/a/:id", "a", WithBarePrefix()) }, ShouldPanicWith, "'/a/:id' must end with the wildcard '*' to match the bare prefix")
+		So(func() { r.Register("GET", "/files", "a") }, ShouldPanicWith, "the current path '/files' handler has been registered")
+
+		// 根路径的'*'通配符已经匹配"/"
+		r.Register("POST", "/*all", "all", WithBarePrefix())
+		m = r.Match("POST", "/")
+		So(m.Route.Handler, ShouldEqual, "all")
+		So(len(m.Params), ShouldEqual, 1)
+		So(ParamValue(m.Params, "all"), ShouldEqual, "")
+	})
+	Convey("Priority", t, func() {
+		r := New()
+		r.Register("GET", "/users/:id", "user")
+		So(func() { r.Register("GET", "/users/new", "new") }, ShouldPanicWith, "'/users/new' conflict with the registered path '/users/:id'")
+
+		r.Register("GET", "/users/new", "new", WithPriority(0))
+		r.Register("GET", "/users/*path", "fallback", WithPriority(0))
+		r.Register("GET", "/users/:name.:ext", "json", WithPriority(0))
+		r.Register("GET", "/users/admin", "admin", WithPriority(-1))
+
+		// 优先级相同时按照具体程度选择：静态 > 包含静态字符的参数 > 参数 > '*'通配符
+		So(r.Match("GET", "/users/new").Route.Handler, ShouldEqual, "new")
+		So(r.Match("GET", "/users/a.json").Route.Handler, ShouldEqual, "json")
+		So(r.Match("GET", "/users/1").Route.Handler, ShouldEqual, "user")
+		So(r.Match("GET", "/users/1/posts").Route.Handler, ShouldEqual, "fallback")
+		// 优先级低于参数路由的静态路由不会被选中
+		So(r.Match("GET", "/users/admin").Route.Handler, ShouldEqual, "user")
+
+		var order []interface{}
+		for _, c := range r.Candidates("GET", "/users/admin") {
+			order = append(order, c.Route.Handler)
+		}
+		So(order, ShouldResemble, []interface{}{"user", "fallback", "admin"})
+
+		c := r.Candidates("GET", "/users/a.json")
+		So(len(c), ShouldEqual, 3)
+		So(c[0].Route.Priority(), ShouldEqual, 0)
+		So(c[0].Path, ShouldEqual, "/users/:name.:ext")
+		So(ParamValue(c[0].Params, "name"), ShouldEqual, "a")
+		So(ParamValue(c[1].Params, "id"), ShouldEqual, "a.json")
+
+		m := r.Match("GET", "/users")
+		So(m.Route, ShouldBeNil)
+		So(m.Redirect, ShouldBeTrue)
+
+		var n int
+		r.Walk(func(route *Route[interface{}]) error {
+			n++
+			return nil
+		})
+		So(n, ShouldEqual, 5)
+
+		So(func() { r.Register("GET", "/users/new", "x", WithPriority(1)) }, ShouldPanicWith, "the current path '/users/new' handler has been registered")
+
+		// 与注册的先后顺序无关，未设置优先级的路由与设置了优先级的路由冲突时同样注册到后续的层中
+		r = New()
+		r.Register("GET", "/items/:id", "item", WithPriority(1))
+		r.Register("GET", "/items/new", "new")
+		So(r.Match("GET", "/items/new").Route.Handler, ShouldEqual, "item")
+		So(r.Match("GET", "/items/1").Route.Handler, ShouldEqual, "item")
+		So(func() { r.Register("GET", "/items/:name", "x") }, ShouldPanicWith, "'/items/:name' conflict with the registered path '/items/new'")
+
+		// 同一层树中'*'通配符本身与其静态后缀重叠的路由同样是候选路由
+		r = New()
+		r.Register("GET", "/repos/*path", "repo")
+		r.Register("GET", "/repos/*path/-/blob/*file", "blob")
+		r.Register("GET", "git.example.com/repos/*path/-/blob/*file", "git")
+		order = nil
+		for _, c := range r.Candidates("GET", "/repos/a/-/blob/b") {
+			order = append(order, c.Route.Handler)
+		}
+		So(order, ShouldResemble, []interface{}{"blob", "repo"})
+		order = nil
+		for _, c := range r.CandidatesHost("GET", "git.example.com", "/repos/a/-/blob/b") {
+			order = append(order, c.Route.Handler)
+		}
+		So(order, ShouldResemble, []interface{}{"git", "blob", "repo"})
+		c = r.Candidates("GET", "/repos/a/-/blob/b")
+		So(ParamValue(c[1].Params, "path"), ShouldEqual, "a/-/blob/b")
+	})
+	Convey("Explain", t, func() {
+		r := New()
+		r.Register("GET", "/users/:id/posts", "posts")
+		r.Register("GET", "/users/:id", "user")
+		r.Register("GET", "/files/*path", "files")
+
+		trace := r.Explain("GET", "/users/1/posts")
+		So(trace.Pattern, ShouldEqual, "/users/:id/posts")
+		So(trace.Params, ShouldResemble, map[string]string{"id": "1"})
+		So(trace.Reason, ShouldBeEmpty)
+		So(trace.Steps, ShouldResemble, []TraceStep{
+			{Node: "/", Path: "/users/1/posts", Prefix: "/", Branch: "take the child starting with 'u'"},
+			{Node: "users/", Path: "users/1/posts", Prefix: "users/", Branch: "take the wildcard child"},
+			{Node: ":id", Path: "1/posts", Prefix: "1", Branch: "take the child starting with '/'"},
+			{Node: "/posts", Path: "/posts", Prefix: "/posts", Branch: "the path ends at the node"},
+		})
+
+		trace = r.Explain("GET", "/users/1/")
+		So(trace.Pattern, ShouldBeEmpty)
+		So(trace.Redirect, ShouldBeTrue)
+		So(trace.Reason, ShouldEqual, "the path diverges from the node '/posts' at offset 1")
+		So(len(trace.Steps), ShouldEqual, 4)
+
+		So(r.Explain("GET", "/nope").Reason, ShouldEqual, "no child of the node '/' starts with 'n'")
+		So(r.Explain("POST", "/users/1").Reason, ShouldEqual, "no route registered for the method 'POST'")
+		So(r.Explain("GET", "/files/a/b").Params, ShouldResemble, map[string]string{"path": "a/b"})
+
+		r.Register("GET", "/users/new", "new", WithPriority(0))
+		trace = r.Explain("GET", "/users/new")
+		So(trace.Pattern, ShouldEqual, "/users/new")
+		So(trace.Steps[len(trace.Steps)-1].Layer, ShouldEqual, 1)
+		So(r.Explain("GET", "/users/1/x").Reason, ShouldEqual, "layer 0: the path diverges from the node '/posts' at offset 1; layer 1: the path diverges from the node '/users/new' at offset 7")
+
+		r.Register("GET", "api.example.com/users/:id", "api")
+		trace = r.ExplainHost("GET", "api.example.com", "/users/1")
+		So(trace.Host, ShouldEqual, "api.example.com")
+		So(trace.Pattern, ShouldEqual, "/users/:id")
+		So(trace.Steps[0].Host, ShouldEqual, "api.example.com")
+
+		// host对应的树未命中时继续在不带host的树中查找
+		trace = r.ExplainHost("GET", "api.example.com", "/files/a")
+		So(trace.Pattern, ShouldEqual, "/files/*path")
+		So(trace.Steps[0].Host, ShouldEqual, "api.example.com")
+		So(trace.Steps[len(trace.Steps)-1].Host, ShouldBeEmpty)
+
+		So(r.ExplainHost("GET", "www.example.com", "/nope").Reason, ShouldEqual, "host 'www.example.com': no route registered for the method 'GET'; layer 0: no child of the node '/' starts with 'n'; layer 1: the path diverges from the node '/users/new' at offset 1")
+		trace = r.ExplainHost("GET", "api.example.com", "/users/1/")
+		So(trace.Redirect, ShouldBeTrue)
+		So(trace.Reason, ShouldStartWith, "host 'api.example.com': ")
+	})
+	Convey("Suggest", t, func() {
+		r := New()
+		r.Register("GET", "/users/:id", "user")
+		r.Register("GET", "/users/:id/posts", "posts")
+		r.Register("GET", "/orders/{id}", "order")
+		r.Register("GET", "/files/*path", "files")
+		r.Register("GET", "/docs/:page.:ext", "docs")
+		r.Register("POST", "/users", "create")
+
+		So(r.Suggest("GET", "/user/1", 3), ShouldResemble, []string{"/users/:id", "/orders/{id}", "/files/*path"})
+		So(r.Suggest("GET", "/users/1/post", 1), ShouldResemble, []string{"/users/:id/posts"})
+		So(r.Suggest("GET", "/file/a/b/c", 1), ShouldResemble, []string{"/files/*path"})
+		So(r.Suggest("GET", "/doc/intro.html", 1), ShouldResemble, []string{"/docs/:page.:ext"})
+		So(r.Suggest("GET", "/x", 3), ShouldBeEmpty)
+		So(r.Suggest("GET", "/user/1", 0), ShouldBeEmpty)
+		So(r.Suggest("PUT", "/users", 3), ShouldBeEmpty)
+
+		r.Register("GET", "/users/:id/comments", "comments", WithPublic())
+		r.Register("GET", "/users/:id/(old/)likes", "likes")
+		So(r.Suggest("GET", "/users/1/comment", 3), ShouldResemble, []string{"/users/:id/comments", "/files/*path", "/users/:id/(old/)likes"})
+		So(r.SuggestPublic("GET", "/users/1/comment", 3), ShouldResemble, []string{"/users/:id/comments"})
+		So(r.Suggest("GET", "/users/1/old/like", 1), ShouldResemble, []string{"/users/:id/(old/)likes"})
+	})
+	Convey("Name", t, func() {
+		r := New()
+		r.Register("GET", "/users/:id", "user", WithName("user"))
+		r.Register("POST", "/users", "create")
+
+		So(r.Match("GET", "/users/1").Route.Name(), ShouldEqual, "user")
+		So(r.Match("POST", "/users").Route.Name(), ShouldBeEmpty)
+		So(func() { r.Register("PUT", "/users/:id", "x", WithName("user")) }, ShouldPanicWith, "the route name 'user' has been used by 'GET /users/:id'")
+		So(func() { WithName("") }, ShouldPanicWith, "route name must not be empty")
+
+		So(r.Dump(), ShouldEqual, "GET\n/users/\n|\n:id [#]\n\nPOST\n/users [#]")
+	})
+	Convey("Scopes", t, func() {
+		r := New()
+		r.Register("GET", "/admin", "admin", WithScopes("admin", "audit"))
+		r.Register("GET", "/health", "health", WithPublic())
+		r.Register("GET", "example.com/open", "open")
+		r.Register("POST", "/open", "open")
+
+		route := r.Match("GET", "/admin").Route
+		So(route.Scopes(), ShouldResemble, []string{"admin", "audit"})
+		So(route.Public(), ShouldBeFalse)
+		So(r.Match("GET", "/health").Route.Public(), ShouldBeTrue)
+
+		So(CheckPolicies(r), ShouldBeError, "routes without a declared policy: 'GET example.com/open', 'POST /open'")
+		So(func() { r.Register("GET", "/a", "a", WithPublic(), WithScopes("x")) }, ShouldPanicWith, "'/a' declared as public must not require scopes")
+		So(func() { WithScopes() }, ShouldPanicWith, "scopes must not be empty")
+	})
+	Convey("Alias", t, func() {
+		r := New()
+		r.Register("GET", "/users/:id", "user", WithName("user"))
+		r.Alias("user", "/v1/users/:id", DeprecatedAlias())
+		r.Alias("user", "GET /u/{id}")
+
+		m := r.Match("GET", "/v1/users/1")
+		So(m.Route.Handler, ShouldEqual, "user")
+		So(m.Route.Path, ShouldEqual, "/users/:id")
+		So(m.Alias, ShouldEqual, "/v1/users/:id")
+		So(m.Deprecated, ShouldBeTrue)
+		So(ParamValue(m.Params, "id"), ShouldEqual, "1")
+
+		m = r.Match("GET", "/u/2")
+		So(m.Alias, ShouldEqual, "/u/{id}")
+		So(m.Deprecated, ShouldBeFalse)
+
+		m = r.Match("GET", "/users/1")
+		So(m.Alias, ShouldBeEmpty)
+		So(m.Route.Aliases(), ShouldResemble, []string{"/v1/users/:id", "/u/{id}"})
+
+		u, err := r.URL("user", URLArgs{Params: map[string]string{"id": "1"}})
+		So(err, ShouldBeNil)
+		So(u.String(), ShouldEqual, "/users/1")
+
+		var n int
+		r.Walk(func(route *Route[interface{}]) error {
+			n++
+			return nil
+		})
+		So(n, ShouldEqual, 1)
+
+		So(func() { r.Alias("x", "/x") }, ShouldPanicWith, "no route named 'x'")
+		So(func() { r.Alias("user", "POST /x/:id") }, ShouldPanicWith, "method 'POST' of the alias conflict with the method 'GET' of the route 'user'")
+		So(func() { r.Alias("user", "/x/:name") }, ShouldPanicWith, "the param 'name' of the alias '/x/:name' is not defined by the route 'user'")
+		So(func() { r.Alias("user", "/v1/users/:id") }, ShouldPanicWith, "the current path '/v1/users/:id' handler has been registered")
+	})
+	Convey("Redirect", t, func() {
+		r := New()
+		r.Register("GET", "/new/:id", "new")
+		r.Redirect("GET", "/old/{id}", "/new/{id}", http.StatusFound)
+
+		m := r.Match("GET", "/old/1")
+		So(m.Route.Handler, ShouldBeNil)
+		So(m.Route.Public(), ShouldBeTrue)
+		So(m.Route.RedirectRule(), ShouldResemble, &RedirectRule{To: "/new/{id}", Code: http.StatusFound})
+		So(m.Route.RedirectRule().Location(m.Params, "a=1"), ShouldEqual, "/new/1?a=1")
+		So(r.Match("GET", "/new/1").Route.RedirectRule(), ShouldBeNil)
+		// Lookup不返回没有handler的重定向路由
+		h, params, tsr := r.Lookup("GET", "/old/1")
+		So(h, ShouldBeNil)
+		So(params, ShouldBeNil)
+		So(tsr, ShouldBeFalse)
+
+		So(func() { r.Redirect("GET", "/a", "/b", http.StatusOK) }, ShouldPanicWith, "invalid redirect code 200")
+		So(func() { r.Redirect("GET", "/a", "b", http.StatusFound) }, ShouldPanicWith, "the redirect target 'b' must start with '/' or be an absolute URL")
+		So(func() { r.Redirect("GET", "/a/:id", "/b/:name", http.StatusFound) }, ShouldPanicWith, "the param 'name' of the redirect target '/b/:name' is not defined by '/a/:id'")
+		So(func() { r.Redirect("GET", "/old/:name", "/b", http.StatusFound) }, ShouldPanicWith, "'/old/:name' conflict with the registered path '/old/:id'")
+	})
+	Convey("Rewrite", t, func() {
+		r := New()
+		r.Register("GET", "/api/v3/*rest", "v3")
+		r.Register("POST", "/api/v3/*rest", "v3_post")
+		r.Register("GET", "/users/:id", "user")
+		r.Rewrite("/api/latest/*rest", "/api/v3/*rest")
+		r.Rewrite("GET /latest/{rest...}", "/api/latest/{rest...}")
+		r.Rewrite("/me/:id", "/users/:id/")
+
+		m := r.Match("GET", "/latest/items/1")
+		So(m.Route.Handler, ShouldEqual, "v3")
+		So(ParamValue(m.Params, "rest"), ShouldEqual, "items/1")
+		So(m.Path, ShouldEqual, "/api/v3/items/1")
+		So(m.OriginalPath, ShouldEqual, "/latest/items/1")
+
+		So(r.Match("POST", "/api/latest/a").Route.Handler, ShouldEqual, "v3_post")
+		So(r.Match("POST", "/latest/a").Route, ShouldBeNil)
+
+		m = r.Match("GET", "/users/1")
+		So(m.Path, ShouldEqual, "/users/1")
+		So(m.OriginalPath, ShouldBeEmpty)
+
+		// 重写后的路径只能通过添加或删除尾部'/'匹配时不会重定向
+		m = r.Match("GET", "/me/1")
+		So(m.Route, ShouldBeNil)
+		So(m.Redirect, ShouldBeFalse)
+
+		trace := r.Explain("GET", "/latest/a")
+		So(trace.Path, ShouldEqual, "/api/v3/a")
+		So(trace.OriginalPath, ShouldEqual, "/latest/a")
+		So(trace.Pattern, ShouldEqual, "/api/v3/*rest")
+
+		// 重写规则不区分host，对host对应的路由同样生效
+		r.Register("GET", "api.example.com/api/v3/*rest", "v3_api")
+		c := r.CandidatesHost("GET", "api.example.com", "/latest/a")
+		So(len(c), ShouldEqual, 2)
+		So(c[0].Route.Handler, ShouldEqual, "v3_api")
+		So(c[1].Route.Handler, ShouldEqual, "v3")
+		So(ParamValue(c[0].Params, "rest"), ShouldEqual, "a")
+		So(len(r.Candidates("GET", "/latest/a")), ShouldEqual, 1)
+
+		trace = r.ExplainHost("GET", "api.example.com", "/latest/a")
+		So(trace.Path, ShouldEqual, "/api/v3/a")
+		So(trace.OriginalPath, ShouldEqual, "/latest/a")
+		So(trace.Host, ShouldEqual, "api.example.com")
+		So(trace.Pattern, ShouldEqual, "/api/v3/*rest")
+		So(trace.Steps[0].Host, ShouldEqual, "api.example.com")
+
+		r.Rewrite("/loop/a", "/loop/b")
+		r.Rewrite("/loop/b", "/loop/a")
+		m = r.Match("GET", "/loop/a")
+		So(m.Route, ShouldBeNil)
+		So(m.RewriteErr, ShouldBeError, "rewrite loop: /loop/a -> /loop/b -> /loop/a")
+
+		r.Rewrite("/deep/*rest", "/deep/x/*rest")
+		So(r.Match("GET", "/deep/1").RewriteErr, ShouldBeError, "rewrite depth exceeds 8: /deep/1 -> /deep/x/1 -> /deep/x/x/1 -> /deep/x/x/x/1 -> /deep/x/x/x/x/1 -> /deep/x/x/x/x/x/1 -> /deep/x/x/x/x/x/x/1 -> /deep/x/x/x/x/x/x/x/1 -> /deep/x/x/x/x/x/x/x/x/1 -> /deep/x/x/x/x/x/x/x/x/x/1")
+
+		So(func() { r.Rewrite("example.com/a", "/b") }, ShouldPanicWith, "the rewrite pattern 'example.com/a' must not contain a host")
+		So(func() { r.Rewrite("/a", "b") }, ShouldPanicWith, "the rewrite target 'b' must be a path starting with '/'")
+		So(func() { r.Rewrite("/a/:id", "/b/:name") }, ShouldPanicWith, "the param 'name' of the rewrite target '/b/:name' is not defined by '/a/:id'")
+	})
+	Convey("Matrix", t, func() {
+		r := New(WithMatrixParams())
+		r.Register("GET", "/cars/models", "models")
+		r.Register("GET", "/trucks/:id/parts", "parts")
+		r.Rewrite("/autos/*rest", "/cars/*rest")
+
+		m := r.Match("GET", "/cars;color=red;used/models;year=2020")
+		So(m.Route.Handler, ShouldEqual, "models")
+		So(m.Path, ShouldEqual, "/cars/models")
+		So(m.OriginalPath, ShouldBeEmpty)
+		So(m.Matrix, ShouldResemble, [][]UrlParam{
+			{{Key: []byte("color"), Value: []byte("red")}, {Key: []byte("used"), Value: []byte("")}},
+			{{Key: []byte("year"), Value: []byte("2020")}},
+		})
+
+		m = r.Match("GET", "/trucks/1;v=2/parts")
+		So(ParamValue(m.Params, "id"), ShouldEqual, "1")
+		So(len(m.Matrix), ShouldEqual, 2)
+		So(m.Matrix[0], ShouldBeNil)
+		So(ParamValue(m.Matrix[1], "v"), ShouldEqual, "2")
+
+		m = r.Match("GET", "/autos;x=1/models")
+		So(m.Route.Handler, ShouldEqual, "models")
+		So(m.OriginalPath, ShouldEqual, "/autos;x=1/models")
+		So(ParamValue(m.Matrix[0], "x"), ShouldEqual, "1")
+
+		m = r.Match("GET", "/cars/models")
+		So(m.Matrix, ShouldBeNil)
+
+		// 只去掉了矩阵参数时仍然返回尾部'/'重定向
+		m = r.Match("GET", "/cars;a=1/models/")
+		So(m.Route, ShouldBeNil)
+		So(m.Redirect, ShouldBeTrue)
+		m = r.Match("GET", "/autos;a=1/models/")
+		So(m.Route, ShouldBeNil)
+		So(m.Redirect, ShouldBeFalse)
+
+		So(r.Explain("GET", "/cars;a=1/models").Pattern, ShouldEqual, "/cars/models")
+		So(New().Match("GET", "/cars;color=red/models").Route, ShouldBeNil)
+	})
+	Convey("Format", t, func() {
+		r := New(WithFormatSuffix("json", "csv", "xml"))
+		r.Register("GET", "/reports/:id", "report_json", WithFormats("json"))
+		r.Register("GET", "/reports/:id", "report_csv", WithFormats("csv"))
+		r.Register("GET", "/exports/:id", "export")
+		r.Register("GET", "/feeds/:id", "feed_xml", WithFormats("xml"))
+		r.Register("GET", "/static/*file", "static")
+
+		m := r.Match("GET", "/reports/1.json")
+		So(m.Route.Handler, ShouldEqual, "report_json")
+		So(m.Format, ShouldEqual, "json")
+		So(ParamValue(m.Params, "id"), ShouldEqual, "1")
+		So(r.Match("GET", "/reports/1.csv").Route.Handler, ShouldEqual, "report_csv")
+		So(r.Match("GET", "/reports/1.xml").Route, ShouldBeNil)
+		So(r.Match("GET", "/reports/1").Route, ShouldBeNil)
+
+		m = r.Match("GET", "/exports/1.csv")
+		So(m.Route.Handler, ShouldEqual, "export")
+		So(m.Format, ShouldEqual, "csv")
+		So(ParamValue(m.Params, "id"), ShouldEqual, "1")
+		m = r.Match("GET", "/exports/1.txt")
+		So(m.Format, ShouldBeEmpty)
+		So(ParamValue(m.Params, "id"), ShouldEqual, "1.txt")
+
+		// 以'*'通配符结尾的路由不分离后缀，除非通过WithFormats声明了格式
+		m = r.Match("GET", "/static/a/b.json")
+		So(ParamValue(m.Params, "file"), ShouldEqual, "a/b.json")
+		So(m.Format, ShouldBeEmpty)
+		r.Register("GET", "/dumps/*name", "dump", WithFormats("csv"))
+		m = r.Match("GET", "/dumps/a/b.csv")
+		So(ParamValue(m.Params, "name"), ShouldEqual, "a/b")
+		So(m.Format, ShouldEqual, "csv")
+
+		So(r.Explain("GET", "/feeds/1").Reason, ShouldEqual, "every route of the matched path requires a format suffix")
+		trace := r.Explain("GET", "/reports/1.csv")
+		So(trace.Pattern, ShouldEqual, "/reports/:id")
+		So(trace.Format, ShouldEqual, "csv")
+		So(trace.Params, ShouldResemble, map[string]string{"id": "1"})
+		So(r.Explain("GET", "/static/a.json").Params, ShouldResemble, map[string]string{"file": "a.json"})
+
+		c := r.Candidates("GET", "/reports/1.csv")
+		So(len(c), ShouldEqual, 1)
+		So(c[0].Route.Handler, ShouldEqual, "report_csv")
+		So(c[0].Format, ShouldEqual, "csv")
+		c = r.Candidates("GET", "/exports/1.json")
+		So(len(c), ShouldEqual, 1)
+		So(ParamValue(c[0].Params, "id"), ShouldEqual, "1")
+
+		So(r.Dump(), ShouldContainSubstring, ":id [#json] [#csv]")
+
+		r.Register("GET", "/summaries/:id", "summary", WithName("summary"), WithFormats("json", "csv"))
+		u, err := r.URL("summary", URLArgs{Params: map[string]string{"id": "1"}})
+		So(err, ShouldBeNil)
+		So(u.String(), ShouldEqual, "/summaries/1.json")
+		u, err = r.URL("summary", URLArgs{Params: map[string]string{"id": "1"}, Format: "csv"})
+		So(err, ShouldBeNil)
+		So(u.String(), ShouldEqual, "/summaries/1.csv")
+		_, err = r.URL("summary", URLArgs{Params: map[string]string{"id": "1"}, Format: "xml"})
+		So(err, ShouldBeError, "the route 'summary' does not handle the format 'xml'")
+
+		var n int
+		r.Walk(func(route *Route[interface{}]) error {
+			n++
+			return nil
+		})
+		So(n, ShouldEqual, 7)
+
+		So(func() { r.Register("GET", "/reports/:id", "x", WithFormats("csv", "xml")) }, ShouldPanicWith, "the format 'csv' of '/reports/:id' conflict with the route 'GET /reports/:id'")
+		So(func() { r.Register("GET", "/exports/:id", "x") }, ShouldPanicWith, "the current path '/exports/:id' handler has been registered")
+		So(func() { r.Register("GET", "/files/:id.json", "x", WithFormats("csv")) }, ShouldPanicWith, "'/files/:id.json' ends with the format suffix '.json' and must not declare other formats with WithFormats")
+		So(func() { r.Register("GET", "/files(.csv)", "x") }, ShouldPanicWith, "the expanded paths of '/files(.csv)' must end with the same format suffix")
+		So(func() { r.Register("GET", "/files/:id", "x", WithFormats("pdf")) }, ShouldPanicWith, "the format 'pdf' is not enabled by WithFormatSuffix")
+		So(func() { New().Register("GET", "/files/:id", "x", WithFormats("json")) }, ShouldPanicWith, "WithFormats requires the router option WithFormatSuffix")
+		So(func() { WithFormatSuffix("a.b") }, ShouldPanicWith, "invalid format suffix 'a.b'")
+
+		// 以格式后缀结尾的路由视为在去掉后缀的路径上声明了该格式
+		r = New(WithFormatSuffix("json", "csv"))
+		r.Register("GET", "/reports/:id.json", "report_json", WithName("report_json"))
+		r.Register("GET", "/reports/:id.csv", "report_csv")
+		r.Register("GET", "/openapi.json", "openapi")
+		m = r.Match("GET", "/reports/1.json")
+		So(m.Route.Handler, ShouldEqual, "report_json")
+		So(m.Route.Formats(), ShouldResemble, []string{"json"})
+		So(m.Format, ShouldEqual, "json")
+		So(ParamValue(m.Params, "id"), ShouldEqual, "1")
+		So(r.Match("GET", "/reports/1.csv").Route.Handler, ShouldEqual, "report_csv")
+		So(r.Match("GET", "/reports/1").Route, ShouldBeNil)
+		So(r.Match("GET", "/openapi.json").Route.Handler, ShouldEqual, "openapi")
+		So(r.Match("GET", "/openapi").Route, ShouldBeNil)
+		u, err = r.URL("report_json", URLArgs{Params: map[string]string{"id": "1"}})
+		So(err, ShouldBeNil)
+		So(u.String(), ShouldEqual, "/reports/1.json")
+		So(func() { r.Register("GET", "/reports/:id.json", "x") }, ShouldPanicWith, "the format 'json' of '/reports/:id' conflict with the route 'GET /reports/:id.json'")
+	})
+}
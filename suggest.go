@@ -0,0 +1,239 @@
+package router
+
+import (
+	"sort"
+	"strings"
+)
+
+// Suggest 返回与path最接近的至多n个已注册路由的路径模式，用于在404响应中提示"did you mean"
+// 路由与path之间的距离为以路径段为单位的编辑距离：插入或删除一个路径段的代价为1，
+// 替换静态路径段的代价为两个路径段之间字符编辑距离与较长者长度的比值，参数路径段能匹配任意路径段，'*'通配符能匹配一个或多个路径段，
+// 结果按距离升序排列，距离相同时按路径模式字典序排列，
+// 距离超过path路径段数一半（至少为1）或不小于路由中静态路径段数的路由不会返回，避免仅靠通配符匹配的路由出现在结果中
+func (r *trieRouter[H]) Suggest(method, path string, n int) []string {
+	return r.suggest(method, path, n, nil)
+}
+
+func (r *trieRouter[H]) SuggestPublic(method, path string, n int) []string {
+	return r.suggest(method, path, n, func(route *Route[H]) bool { return route.public })
+}
+
+// 与Suggest相同，keep不为nil时只返回keep返回true的路由
+func (r *trieRouter[H]) suggest(method, path string, n int, keep func(route *Route[H]) bool) []string {
+	if n <= 0 {
+		return nil
+	}
+
+	w := suggestWalker{segs: splitSegments(path)}
+	w.limit = float64(len(w.segs)) / 2
+	if w.limit < 1 {
+		w.limit = 1
+	}
+	row := make([]float64, len(w.segs)+1)
+	for j := range row {
+		row[j] = float64(j)
+	}
+
+	// 包含可选部分的路由取各展开路径中的最小距离
+	dist := make(map[*Route[H]]float64)
+	w.fn = func(h interface{}, d float64) {
+		l := h.(*leaf[H])
+		if keep != nil && !keep(l.route) {
+			return
+		}
+		if old, ok := dist[l.route]; !ok || d < old {
+			dist[l.route] = d
+		}
+	}
+	for _, root := range r.trees[treeKey{method: method}] {
+		w.walk(root, nil, row, 0)
+	}
+
+	type suggestion struct {
+		pattern string
+		dist    float64
+	}
+	ss := make([]suggestion, 0, len(dist))
+	for route, d := range dist {
+		ss = append(ss, suggestion{pattern: route.Path, dist: d})
+	}
+	sort.Slice(ss, func(i, j int) bool {
+		if ss[i].dist != ss[j].dist {
+			return ss[i].dist < ss[j].dist
+		}
+		return ss[i].pattern < ss[j].pattern
+	})
+
+	if len(ss) > n {
+		ss = ss[:n]
+	}
+	ret := make([]string, 0, len(ss))
+	for _, s := range ss {
+		ret = append(ret, s.pattern)
+	}
+	return ret
+}
+
+// suggestWalker 沿树计算路由与请求路径段之间的编辑距离，路径前缀相同的路由共享已经计算的部分
+type suggestWalker struct {
+	segs  []string
+	limit float64
+	fn    func(h interface{}, d float64)
+}
+
+// 遍历以n为根的子树，seg为n之前尚未结束的路由路径段，row为已结束的路由路径段与segs之间的编辑距离，见nextRow，static为其中的静态路径段数
+// row中的最小值不会随后续的路径段减小，超过limit时不再遍历子树
+func (w *suggestWalker) walk(n *node, seg []byte, row []float64, static int) {
+	seg = seg[:len(seg):len(seg)]
+	for _, c := range n.path {
+		if c != '/' {
+			seg = append(seg, c)
+			continue
+		}
+		if len(seg) == 0 {
+			continue
+		}
+		row, static = w.next(row, string(seg), static)
+		if minRow(row) > w.limit {
+			return
+		}
+		seg = nil
+	}
+
+	if n.handler != nil {
+		r, s := row, static
+		if len(seg) > 0 {
+			r, s = w.next(row, string(seg), static)
+		}
+		// 距离不小于静态路径段数的路由仅靠通配符匹配，不返回
+		if d := r[len(w.segs)]; d <= w.limit && d < float64(s) {
+			w.fn(n.handler, d)
+		}
+	}
+	for _, v := range n.children {
+		w.walk(v, seg, row, static)
+	}
+}
+
+// 返回加入路由路径段p之后的编辑距离及静态路径段数
+func (w *suggestWalker) next(row []float64, p string, static int) ([]float64, int) {
+	if strings.IndexAny(p, ":*") < 0 {
+		static++
+	}
+	return nextRow(row, p, w.segs), static
+}
+
+// 返回path中的各个路径段，忽略开头和末尾的'/'
+func splitSegments(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// row[j]为路由的前i个路径段与segs[:j]之间的编辑距离，返回加入路由的第i+1个路径段p之后的编辑距离
+func nextRow(row []float64, p string, segs []string) []float64 {
+	next := make([]float64, len(row))
+	next[0] = row[0] + 1
+	catchAll := isCatchAll(p)
+	for j := 1; j <= len(segs); j++ {
+		d := minFloat(row[j]+1, next[j-1]+1)
+		if catchAll {
+			// '*'通配符匹配segs[j-1]，并且可以继续匹配之前的路径段
+			d = minFloat(d, minFloat(row[j-1], next[j-1]))
+		} else {
+			d = minFloat(d, row[j-1]+substituteCost(p, segs[j-1]))
+		}
+		next[j] = d
+	}
+	return next
+}
+
+func minRow(row []float64) float64 {
+	ret := row[0]
+	for _, v := range row[1:] {
+		ret = minFloat(ret, v)
+	}
+	return ret
+}
+
+// 返回将路由路径段p替换为请求路径段s的代价
+func substituteCost(p, s string) float64 {
+	if strings.IndexByte(p, ':') >= 0 || strings.HasPrefix(p, "*") {
+		if matchSegment(p, s) {
+			return 0
+		}
+		return 1
+	}
+	if p == s {
+		return 0
+	}
+	l := len(p)
+	if len(s) > l {
+		l = len(s)
+	}
+	return float64(editDistance(p, s)) / float64(l)
+}
+
+// 返回包含参数的路由路径段p能否匹配请求路径段s，参数值截止到参数之后的第1个静态字符，与查找时的规则相同
+func matchSegment(p, s string) bool {
+	for len(p) > 0 {
+		if p[0] != ':' && p[0] != '*' {
+			if len(s) == 0 || s[0] != p[0] {
+				return false
+			}
+			p, s = p[1:], s[1:]
+			continue
+		}
+
+		j := 1
+		for j < len(p) && isParamNameChar(p[j]) {
+			j++
+		}
+		p = p[j:]
+		if len(p) == 0 {
+			return true
+		}
+		k := strings.IndexByte(s, p[0])
+		if k < 0 {
+			return false
+		}
+		s = s[k:]
+	}
+	return len(s) == 0
+}
+
+// 返回a与b之间的字符编辑距离
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = prev[j-1] + cost
+			if d := prev[j] + 1; d < cur[j] {
+				cur[j] = d
+			}
+			if d := cur[j-1] + 1; d < cur[j] {
+				cur[j] = d
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"sort"
+	"strconv"
 )
 
 type node struct {
@@ -35,13 +36,51 @@ func (n *node) Register(path []byte, h interface{}) {
 
 		// 至此，n.path和path的公共前缀的长度必大于0
 		l := longestCommonPrefix(n.path, path)
-		if n.path[0] == ':' {
+		if n.path[0] == ':' || n.isAnonymous() {
 			// 此时必须满足如下条件：
-			// n.path和path的公共前缀的长度等于n.path的长度且如果path的长度大于公共前缀的长度则path中位于公共前缀之后的首字符必须为'/'
+			// n.path和path的公共前缀的长度等于n.path的长度且如果path的长度大于公共前缀的长度则path中位于公共前缀之后的首字符不能为参数名字符
+			if !(l == len(n.path) && (l == len(path) || !isParamNameChar(path[l]))) {
+				treePath.Write(n.getToMostLeftNodePath())
+				panic("'" + fullPath + "' conflict with the registered path '" + treePath.String() + "'")
+			}
+		}
+
+		if n.path[0] == '*' && !n.isAnonymous() {
+			// 与':'相同，通配符名称必须完全相同，通配符之后为以'/'开头的静态后缀
 			if !(l == len(n.path) && (l == len(path) || path[l] == '/')) {
 				treePath.Write(n.getToMostLeftNodePath())
 				panic("'" + fullPath + "' conflict with the registered path '" + treePath.String() + "'")
 			}
+
+			if l < len(path) {
+				treePath.Write(n.path)
+				path = path[l:]
+
+				// '*'结点的孩子为各个静态后缀，后缀之间不共享前缀，按后缀长度降序排列
+				suffix := path
+				if _, idx := findWildcard(path); idx >= 0 {
+					suffix = path[:idx]
+				}
+				if v := n.findSuffix(suffix); v != nil {
+					n = v
+					continue
+				}
+				// 查找时只尝试最后出现的后缀，某个后缀在另一个后缀中的位置不在开头时，后者出现时前者总是出现在其后，后者永远不会被选中
+				for _, v := range n.children {
+					if bytes.Contains(v.path[1:], suffix) || bytes.Contains(suffix[1:], v.path) {
+						treePath.Write(v.getToMostLeftNodePath())
+						panic("'" + fullPath + "' conflict with the registered path '" + treePath.String() + "'")
+					}
+				}
+
+				child := &node{}
+				child.genTree(path, h)
+				n.children = append(n.children, child)
+				for i := len(n.children) - 1; i > 0 && len(n.children[i-1].path) < len(child.path); i-- {
+					n.children[i], n.children[i-1] = n.children[i-1], n.children[i]
+				}
+				return
+			}
 		}
 
 		if l < len(n.path) {
@@ -80,10 +119,6 @@ func (n *node) Register(path []byte, h interface{}) {
 			if n.handler != nil && isWildcardSegment(path) {
 				panic("'" + fullPath + "' conflict with the registered path '" + treePath.String() + "'")
 			}
-			if v.path[0] == '*' {
-				treePath.Write(v.getToMostLeftNodePath())
-				panic("'" + fullPath + "' conflict with the registered path '" + treePath.String() + "'")
-			}
 			n = v
 			continue
 		}
@@ -113,50 +148,152 @@ func (n *node) Register(path []byte, h interface{}) {
 }
 
 func (n *node) Lookup(path []byte) (h interface{}, p []UrlParam, redirect bool) {
+	return n.lookup(path, nil)
+}
+
+// 与Lookup相同，t不为nil时记录查找过程中访问的结点及选择的分支
+// 描述分支及原因的字符串只在t不为nil时生成，使Lookup不会因记录过程而产生额外的内存分配
+func (n *node) lookup(path []byte, t *tracer) (h interface{}, p []UrlParam, redirect bool) {
 	if !(len(path) > 0 && path[0] == '/') {
+		t.fail("first char of the path must be '/'")
 		return nil, nil, false
 	}
-	var np *node // 结点n的父节点
+	return n.match(path, nil, t)
+}
+
+// 在以n为根的子树中查找path，np为结点n的父节点，path不必以'/'开头
+func (n *node) match(path []byte, np *node, t *tracer) (h interface{}, p []UrlParam, redirect bool) {
 walk:
 	for {
-		switch n.path[0] {
+		t.visit(n, path)
+		kind := n.path[0]
+		if n.isAnonymous() {
+			kind = ':'
+		}
+		switch kind {
 		case '*':
+			// 从后向前查找各个静态后缀，只尝试最后出现的后缀，位置相同时较长的后缀优先，通配符的值为后缀之前的部分
+			// 不会回退到其他后缀，使查找时间与路径长度保持线性关系，后缀之后的部分未匹配时只能由通配符本身匹配
+			var suffix *node
+			k := -1
+			for _, v := range n.children {
+				if i := bytes.LastIndex(path, v.path); i > k {
+					suffix, k = v, i
+				}
+			}
+			if suffix != nil {
+				if t != nil {
+					t.branch("try the suffix '" + string(suffix.path) + "' at offset " + strconv.Itoa(k))
+				}
+				h, pp, tsr := suffix.match(path[k:], n, t)
+				if h != nil {
+					p = append(p, UrlParam{
+						Key:   n.path[1:],
+						Value: path[:k],
+					})
+					return h, append(p, pp...), false
+				}
+				redirect = tsr
+				t.visit(n, path)
+			}
+
+			if n.handler == nil {
+				if t != nil {
+					t.fail("no suffix of the wildcard '" + string(n.path) + "' matched the path")
+				}
+				return nil, nil, redirect
+			}
+			if t != nil {
+				t.prefix(path)
+				t.branch("the wildcard '" + string(n.path) + "' matched the rest of the path")
+			}
 			p = append(p, UrlParam{
 				Key:   n.path[1:],
 				Value: path,
 			})
 			return n.handler, p, false
 		case ':':
+			// 参数值截止到'/'或n的某个孩子结点路径的首字符，匿名通配符不产生参数
+			// 先尝试同一路径段中首个能作为分隔符的字符对应的孩子，未匹配时参数值延伸到'/'，
+			// 使"/files/:name/raw"在注册"/files/:name.:ext"之后仍然能匹配"/files/a.b/raw"
+			// n的孩子均以'/'开头时只需查找'/'
+			if n.hasInlineChild() {
+				i := 0
+				for i < len(path) && path[i] != '/' && n.findChildren(path[i]) == nil {
+					i++
+				}
+				if i < len(path) && path[i] != '/' {
+					t.prefix(path[:i])
+					if t != nil {
+						t.branch("try the child starting with '" + string(path[i]) + "'")
+					}
+					h, pp, tsr := n.findChildren(path[i]).match(path[i:], n, t)
+					if h != nil {
+						if !n.isAnonymous() {
+							p = append(p, UrlParam{
+								Key:   n.path[1:],
+								Value: path[:i],
+							})
+						}
+						return h, append(p, pp...), false
+					}
+					redirect = tsr
+					t.visit(n, path)
+				}
+			}
 			for i, c := range path {
 				if c == '/' {
-					p = append(p, UrlParam{
-						Key:   n.path[1:],
-						Value: path[:i],
-					})
+					t.prefix(path[:i])
+					if !n.isAnonymous() {
+						p = append(p, UrlParam{
+							Key:   n.path[1:],
+							Value: path[:i],
+						})
+					}
 					path = path[i:]
 					if v := n.findChildren(path[0]); v != nil {
+						if t != nil {
+							t.branch("take the child starting with '" + string(path[0]) + "'")
+						}
 						np = n
 						n = v
 						continue walk
 					}
 					// 没找到该节点
-					return nil, nil, isSlash(path) && n.canHandle()
+					redirect = redirect || isSlash(path) && n.canHandle()
+					if t != nil {
+						t.fail("no child of the wildcard '" + string(n.path) + "' starts with '" + string(path[0]) + "'")
+					}
+					return nil, nil, redirect
 				}
 			}
 
+			t.prefix(path)
 			if n.handler != nil {
-				p = append(p, UrlParam{
-					Key:   n.path[1:],
-					Value: path,
-				})
+				if t != nil {
+					t.branch("the wildcard '" + string(n.path) + "' matched the last segment")
+				}
+				if !n.isAnonymous() {
+					p = append(p, UrlParam{
+						Key:   n.path[1:],
+						Value: path,
+					})
+				}
 				return n.handler, p, false
 			}
 
 			v := n.findChildren('/')
-			return nil, nil, v.canHandle() && isSlash(v.path)
+			if t != nil {
+				t.fail("the wildcard '" + string(n.path) + "' has no handler")
+			}
+			return nil, nil, redirect || v.canHandle() && isSlash(v.path)
 		default:
 			l := longestCommonPrefix(n.path, path)
+			t.prefix(path[:l])
 			if l < len(n.path) {
+				if t != nil {
+					t.fail("the path diverges from the node '" + string(n.path) + "' at offset " + strconv.Itoa(l))
+				}
 				return nil, nil, (isSlash(path) && np.canHandle()) || (path[len(path)-1] != '/' && l+1 == len(n.path) && n.path[l] == '/' && n.canHandle())
 			}
 
@@ -164,15 +301,20 @@ walk:
 
 			if l == len(path) {
 				if n.handler != nil {
+					t.branch("the path ends at the node")
 					return n.handler, p, false
 				}
 
 				if n.isWildcardParent() && n.children[0].handler != nil {
+					t.branch("the path ends at the node, take the wildcard child with an empty value")
 					n = n.children[0]
 					path = []byte("")
 					continue walk
 				}
 
+				if t != nil {
+					t.fail("the path ends at the node '" + string(n.path) + "' which has no handler")
+				}
 				if path[len(path)-1] == '/' {
 					return nil, nil, isSlash(path) && np.canHandle()
 				}
@@ -193,21 +335,93 @@ walk:
 			path = path[l:]
 
 			if n.isWildcardParent() {
+				t.branch("take the wildcard child")
 				np = n
 				n = n.children[0]
 				continue walk
 			}
 
 			if v := n.findChildren(path[0]); v != nil {
+				if t != nil {
+					t.branch("take the child starting with '" + string(path[0]) + "'")
+				}
 				np = n
 				n = v
 				continue walk
 			}
+			if t != nil {
+				t.fail("no child of the node '" + string(n.path) + "' starts with '" + string(path[0]) + "'")
+			}
 			return nil, nil, n.canHandle() && isSlash(path)
 		}
 	}
 }
 
+// 对以n为根的子树中所有能够匹配path的handler调用fn，p为n之前的结点产生的参数
+// 与Lookup不同的是，'*'结点的各个静态后缀及其本身的handler都会尝试，而不是只使用第1个匹配的结果，用于列出重叠的路由
+func (n *node) lookupAll(path []byte, p []UrlParam, fn func(h interface{}, p []UrlParam)) {
+	kind := n.path[0]
+	if n.isAnonymous() {
+		kind = ':'
+	}
+	switch kind {
+	case '*':
+		for _, v := range n.children {
+			if k := bytes.LastIndex(path, v.path); k >= 0 {
+				v.lookupAll(path[k:], append(p[:len(p):len(p)], UrlParam{Key: n.path[1:], Value: path[:k]}), fn)
+			}
+		}
+		if n.handler != nil {
+			fn(n.handler, append(p[:len(p):len(p)], UrlParam{Key: n.path[1:], Value: path}))
+		}
+	case ':':
+		// 与lookup相同，先尝试同一路径段中首个分隔符对应的孩子，再将参数值延伸到'/'
+		param := func(value []byte) []UrlParam {
+			if n.isAnonymous() {
+				return p
+			}
+			return append(p[:len(p):len(p)], UrlParam{Key: n.path[1:], Value: value})
+		}
+		i := 0
+		if n.hasInlineChild() {
+			for i < len(path) && path[i] != '/' && n.findChildren(path[i]) == nil {
+				i++
+			}
+			if i < len(path) && path[i] != '/' {
+				n.findChildren(path[i]).lookupAll(path[i:], param(path[:i]), fn)
+			}
+		}
+		for i < len(path) && path[i] != '/' {
+			i++
+		}
+		p = param(path[:i])
+		if i == len(path) {
+			if n.handler != nil {
+				fn(n.handler, p)
+			}
+			return
+		}
+		if v := n.findChildren(path[i]); v != nil {
+			v.lookupAll(path[i:], p, fn)
+		}
+	default:
+		if !bytes.HasPrefix(path, n.path) {
+			return
+		}
+		path = path[len(n.path):]
+		switch {
+		case len(path) == 0 && n.handler != nil:
+			fn(n.handler, p)
+		case n.isWildcardParent():
+			n.children[0].lookupAll(path, p, fn)
+		case len(path) > 0:
+			if v := n.findChildren(path[0]); v != nil {
+				v.lookupAll(path, p, fn)
+			}
+		}
+	}
+}
+
 // 将path插入到以为n为根的空树中，要求len(path)>0
 func (n *node) genTree(path []byte, h interface{}) {
 	for {
@@ -246,6 +460,43 @@ func (n *node) findChildren(firstChar byte) *node {
 	return nil
 }
 
+// 返回n是否为匹配单个路径段的匿名通配符结点，其路径为不带名称的'*'，之后的静态字符位于其孩子结点中
+func (n *node) isAnonymous() bool {
+	return len(n.path) == 1 && n.path[0] == '*'
+}
+
+// 返回'*'结点n的孩子中路径与suffix相同的结点
+func (n *node) findSuffix(suffix []byte) *node {
+	for _, v := range n.children {
+		if bytes.Equal(v.path, suffix) {
+			return v
+		}
+	}
+	return nil
+}
+
+// 返回n是否存在不以'/'开头的孩子，即同一路径段中参数之后的静态分隔符
+func (n *node) hasInlineChild() bool {
+	for _, v := range n.children {
+		if v.path[0] != '/' {
+			return true
+		}
+	}
+	return false
+}
+
+// 返回以n为根的子树的副本，注册时只会重新切分结点的路径而不会修改其内容，因此副本与原树共享结点的路径
+func (n *node) clone() *node {
+	c := &node{path: n.path, handler: n.handler}
+	if len(n.children) > 0 {
+		c.children = make([]*node, len(n.children))
+		for i, v := range n.children {
+			c.children[i] = v.clone()
+		}
+	}
+	return c
+}
+
 func (n *node) isLeaf() bool {
 	return len(n.children) == 0
 }
@@ -260,6 +511,21 @@ func (n *node) canHandle() bool {
 	return n != nil && (n.handler != nil || (n.isWildcardParent() && n.children[0].handler != nil))
 }
 
+// 按先序遍历以n为根的子树，对每个存在handler的结点调用fn，fn返回非nil错误时停止遍历并返回该错误
+func (n *node) walk(fn func(h interface{}) error) error {
+	if n.handler != nil {
+		if err := fn(n.handler); err != nil {
+			return err
+		}
+	}
+	for _, v := range n.children {
+		if err := v.walk(fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // 返回n到以n为根的子树中最左边结点的路径
 func (n *node) getToMostLeftNodePath() []byte {
 	buf := bytes.Buffer{}
@@ -277,6 +543,11 @@ func isWildcard(c byte) bool {
 	return c == ':' || c == '*'
 }
 
+// 返回c是否可以作为':'参数名中的字符，参数名为':'之后由此类字符组成的最长串，其后的首个字符即为参数值的分隔符
+func isParamNameChar(c byte) bool {
+	return c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
 func isWildcardSegment(path []byte) bool {
 	if !isWildcard(path[0]) {
 		return false
@@ -298,14 +569,9 @@ func verify(path []byte) error {
 		return errors.New("first char must be '/'")
 	}
 	var lastWildcard byte // 当前路径段的最后一个通配符
-	for i, c := range path[1:] {
+	for i := 1; i < len(path); i++ {
+		c := path[i]
 		if c == '/' {
-			if lastWildcard == '*' {
-				return errors.New("there should be no '/' after the wildcard '*'")
-			}
-			if i > 0 && isWildcard(path[i]) {
-				return errors.New("the name of wildcard segment must not empty")
-			}
 			lastWildcard = 0
 			continue
 		}
@@ -314,18 +580,71 @@ func verify(path []byte) error {
 			continue
 		}
 
-		if lastWildcard != 0 {
+		// 同一路径段中可以存在多个':'，但不能与'*'同时存在
+		if lastWildcard == '*' || (lastWildcard != 0 && c == '*') {
 			return errors.New("the wildcard '*' and ':' should not exist in the same path segment")
 		}
 
-		if c == '*' && i > 0 && path[i] != '/' {
+		if c == '*' && path[i-1] != '/' {
 			return errors.New("the previous character of '*' must be '/'")
 		}
 
+		single := lastWildcard == 0 // 是否为当前路径段中的第1个通配符
 		lastWildcard = c
-	}
-	if isWildcard(path[len(path)-1]) {
-		return errors.New("the name of wildcard segment must not empty")
+		if c == '*' {
+			// '*'之后的名称字符组成通配符的名称，名称必须截止到'/'，其后可以存在以'/'开头的静态后缀
+			// 之后不是名称字符的'*'为匹配单个路径段的匿名通配符，与':'参数相同，其后可以存在同一路径段中的静态字符，如"/a/*.json"
+			j := i + 1
+			for j < len(path) && isParamNameChar(path[j]) {
+				j++
+			}
+			if j == i+1 {
+				lastWildcard = ':'
+				if j < len(path) && path[j] == ':' {
+					return errors.New("the wildcard ':' must be separated from the previous one by a static character")
+				}
+				continue
+			}
+			if j+1 < len(path) && path[j] == '/' && isWildcard(path[j+1]) {
+				// 查找时从后向前定位通配符之后的静态后缀，后缀为空时无法确定通配符的值
+				return errors.New("the wildcard '*" + string(path[i+1:j]) + "' must be followed by a static path segment instead of another wildcard")
+			}
+			if j < len(path) && path[j] != '/' {
+				k := j
+				for k < len(path) && path[k] != '/' {
+					k++
+				}
+				return errors.New("the wildcard '*" + string(path[i+1:j]) + "' must not be followed by the static text '" + string(path[j:k]) +
+					"' in the same segment, wildcard names may only contain letters, digits and '_'")
+			}
+			i = j - 1
+			continue
+		}
+
+		// 跳过参数名，参数名之后的字符为参数值的分隔符
+		j := i + 1
+		for j < len(path) && isParamNameChar(path[j]) {
+			j++
+		}
+		if j == i+1 {
+			return errors.New("the name of wildcard segment must not empty")
+		}
+		if j < len(path) && path[j] == ':' {
+			return errors.New("the wildcard ':' must be separated from the previous one by a static character")
+		}
+		if single && j < len(path) && path[j] != '/' {
+			// 参数之后的静态字符只能作为与同一路径段中下一个通配符之间的分隔符，
+			// 否则"/users/:user-id"这类模式会被静默地解析为参数user及静态后缀"-id"
+			k := j
+			for k < len(path) && path[k] != '/' && !isWildcard(path[k]) {
+				k++
+			}
+			if k == len(path) || path[k] == '/' {
+				return errors.New("the param ':" + string(path[i+1:j]) + "' must not be followed by the static text '" + string(path[j:k]) +
+					"' unless another wildcard follows in the same segment, param names may only contain letters, digits and '_'")
+			}
+		}
+		i = j - 1
 	}
 	return nil
 }
@@ -347,21 +666,15 @@ func longestCommonPrefix(a, b []byte) int {
 // 返回path中的通配符段及其第1个字符在path中的索引，未找到通配符段时返回的索引值小于0
 func findWildcard(path []byte) ([]byte, int) {
 	for i, c := range path {
-		if c == '*' {
-			return path[i:], i
-		}
-
-		if c != ':' {
+		if !isWildcard(c) {
 			continue
 		}
 
-		for j := i + 1; j < len(path); j++ {
-			if path[j] == '/' {
-				return path[i:j], i
-			}
+		j := i + 1
+		for j < len(path) && isParamNameChar(path[j]) {
+			j++
 		}
-
-		return path[i:], i
+		return path[i:j], i
 	}
 	return nil, -1
 }
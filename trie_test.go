@@ -1,12 +1,9 @@
 package router
 
 import (
-	"fmt"
 	"net/http"
 	"testing"
 
-	"github.com/gogokit/treeprint"
-
 	. "github.com/smartystreets/goconvey/convey"
 )
 
@@ -109,7 +106,7 @@ func TestTrie(t *testing.T) {
 				"/a/",
 				"/a/*",
 			}
-			expect := "the name of wildcard segment must not empty"
+			expect := "'/a/*' conflict with the registered path '/a/'"
 			root := &node{}
 			var errMsg string
 			for _, v := range paths {
@@ -492,46 +489,197 @@ func TestTrie(t *testing.T) {
 	})
 }
 
-type rNode struct {
-	ids map[*node]string
-	n   *node
-}
+func TestMultiParamSegment(t *testing.T) {
+	Convey("MultiParamSegment", t, func() {
+		paths := []string{
+			"/files/:name.:ext",
+			"/files/:name/raw",
+			"/v:major-:minor/",
+			"/v:major-:minor/docs",
+		}
+		root := &node{}
+		for _, v := range paths {
+			root.Register([]byte(v), v)
+		}
 
-func (n rNode) Id() string {
-	return n.ids[n.n]
-}
+		cases := []struct {
+			path   string
+			expect string
+			params []string
+		}{
+			{"/files/archive.tar", "/files/:name.:ext", []string{"name", "archive", "ext", "tar"}},
+			{"/files/archive.tar.gz", "/files/:name.:ext", []string{"name", "archive", "ext", "tar.gz"}},
+			{"/files/archive/raw", "/files/:name/raw", []string{"name", "archive"}},
+			// 分隔符对应的孩子未匹配时参数值延伸到'/'
+			{"/files/a.b/raw", "/files/:name/raw", []string{"name", "a.b"}},
+			{"/v1-2/", "/v:major-:minor/", []string{"major", "1", "minor", "2"}},
+			{"/v1-2/docs", "/v:major-:minor/docs", []string{"major", "1", "minor", "2"}},
+		}
+		for _, c := range cases {
+			h, param, tsr := root.Lookup([]byte(c.path))
+			So(h, ShouldEqual, c.expect)
+			So(tsr, ShouldEqual, false)
+			var kv []string
+			for _, p := range param {
+				kv = append(kv, string(p.Key), string(p.Value))
+			}
+			So(kv, ShouldResemble, c.params)
+		}
 
-func (n rNode) Children() (ret []treeprint.Node) {
-	for _, v := range n.n.children {
-		ret = append(ret, rNode{
-			ids: n.ids,
-			n:   v,
-		})
-	}
-	return ret
+		h, _, tsr := root.Lookup([]byte("/v1-2"))
+		So(h, ShouldEqual, nil)
+		So(tsr, ShouldEqual, true)
+
+		h, _, _ = root.Lookup([]byte("/files/archive"))
+		So(h, ShouldEqual, nil)
+
+		So(func() { root.Register([]byte("/files/:file.:ext"), "x") }, ShouldPanicWith, "'/files/:file.:ext' conflict with the registered path '/files/:name.:ext'")
+		So(func() { root.Register([]byte("/a/:x:y"), "x") }, ShouldPanicWith, "the wildcard ':' must be separated from the previous one by a static character")
+		So(func() { root.Register([]byte("/a/:x.*y"), "x") }, ShouldPanicWith, "the wildcard '*' and ':' should not exist in the same path segment")
+		So(func() { root.Register([]byte("/a/:.x"), "x") }, ShouldPanicWith, "the name of wildcard segment must not empty")
+		// 参数之后的静态字符之后不存在通配符时，不能将其静默地视为参数名之后的后缀
+		So(func() { root.Register([]byte("/users/:user-id"), "x") }, ShouldPanicWith, "the param ':user' must not be followed by the static text '-id' unless another wildcard follows in the same segment, param names may only contain letters, digits and '_'")
+		So(func() { root.Register([]byte("/pages/p:id.html/raw"), "x") }, ShouldPanicWith, "the param ':id' must not be followed by the static text '.html' unless another wildcard follows in the same segment, param names may only contain letters, digits and '_'")
+		root.Register([]byte("/pages/:name.:ext.gz"), "gz")
+		h, _, _ = root.Lookup([]byte("/pages/a.tar.gz"))
+		So(h, ShouldEqual, "gz")
+
+		// 匿名通配符同样在分隔符对应的孩子未匹配时延伸到'/'
+		root.Register([]byte("/feeds/*.json/latest"), "json")
+		root.Register([]byte("/feeds/*/:id"), "feed")
+		h, param, _ := root.Lookup([]byte("/feeds/a.json/latest"))
+		So(h, ShouldEqual, "json")
+		So(len(param), ShouldEqual, 0)
+		h, param, _ = root.Lookup([]byte("/feeds/a.json/1"))
+		So(h, ShouldEqual, "feed")
+		So(string(param[0].Value), ShouldEqual, "1")
+		h, _, tsr = root.Lookup([]byte("/feeds/a.json/latest/"))
+		So(h, ShouldEqual, nil)
+		So(tsr, ShouldEqual, true)
+	})
 }
 
-func (n rNode) String() string {
-	return string(n.n.path) + func() string {
-		if n.n.handler != nil {
-			return " [#]"
+func TestMiddleCatchAll(t *testing.T) {
+	Convey("MiddleCatchAll", t, func() {
+		paths := []string{
+			"/repos/*path/-/blob/*file",
+			"/repos/*path/-/tree/*dir",
+			"/repos/*path",
+			"/objects/*key/metadata",
+			"/objects/*key/acl/:user",
 		}
-		return ""
-	}()
+		root := &node{}
+		for _, v := range paths {
+			root.Register([]byte(v), v)
+		}
+
+		cases := []struct {
+			path   string
+			expect string
+			params []string
+		}{
+			{"/repos/a/b/-/blob/main/x.go", "/repos/*path/-/blob/*file", []string{"path", "a/b", "file", "main/x.go"}},
+			{"/repos/a/b/-/tree/main", "/repos/*path/-/tree/*dir", []string{"path", "a/b", "dir", "main"}},
+			{"/repos/a/-/blob/b/-/blob/c", "/repos/*path/-/blob/*file", []string{"path", "a/-/blob/b", "file", "c"}},
+			{"/repos/a/b", "/repos/*path", []string{"path", "a/b"}},
+			{"/objects/a/b/metadata", "/objects/*key/metadata", []string{"key", "a/b"}},
+			{"/objects/a/metadata/metadata", "/objects/*key/metadata", []string{"key", "a/metadata"}},
+			{"/objects/a/b/acl/u1", "/objects/*key/acl/:user", []string{"key", "a/b", "user", "u1"}},
+		}
+		for _, c := range cases {
+			h, param, _ := root.Lookup([]byte(c.path))
+			So(h, ShouldEqual, c.expect)
+			var kv []string
+			for _, p := range param {
+				kv = append(kv, string(p.Key), string(p.Value))
+			}
+			So(kv, ShouldResemble, c.params)
+		}
+
+		h, param, tsr := root.Lookup([]byte("/objects/a/b"))
+		So(h, ShouldEqual, nil)
+		So(len(param), ShouldEqual, 0)
+		So(tsr, ShouldEqual, false)
+
+		// 只尝试最后出现的后缀，其结果中的重定向标志同样返回
+		h, param, _ = root.Lookup([]byte("/objects/a/metadata/acl/u1"))
+		So(h, ShouldEqual, "/objects/*key/acl/:user")
+		So(string(param[0].Value), ShouldEqual, "a/metadata")
+		h, _, tsr = root.Lookup([]byte("/objects/a/b/metadata/"))
+		So(h, ShouldEqual, nil)
+		So(tsr, ShouldEqual, true)
+
+		So(func() { root.Register([]byte("/objects/*name/metadata"), "x") }, ShouldPanicWith, "'/objects/*name/metadata' conflict with the registered path '/objects/*key/metadata'")
+		So(func() { root.Register([]byte("/objects/*key/metadata"), "x") }, ShouldPanicWith, "the current path '/objects/*key/metadata' handler has been registered")
+		So(func() { root.Register([]byte("/objects/*key/:version/info"), "x") }, ShouldPanicWith, "the wildcard '*key' must be followed by a static path segment instead of another wildcard")
+		So(func() { root.Register([]byte("/objects/*key/a/metadata"), "x") }, ShouldPanicWith, "'/objects/*key/a/metadata' conflict with the registered path '/objects/*key/metadata'")
+		So(func() { root.Register([]byte("/objects/*key/tag/acl/:user"), "x") }, ShouldPanicWith, "'/objects/*key/tag/acl/:user' conflict with the registered path '/objects/*key/acl/:user'")
+		So(func() { root.Register([]byte("/repos/*path/blob/*name"), "x") }, ShouldPanicWith, "'/repos/*path/blob/*name' conflict with the registered path '/repos/*path/-/blob/*file'")
+		So(func() { root.Register([]byte("/objects/a/*"), "x") }, ShouldPanicWith, "'/objects/a/*' conflict with the registered path '/objects/*key/metadata'")
+	})
 }
 
-func genIdByDFS(root *node, ids map[*node]string) {
-	ids[root] = fmt.Sprintf("%d", len(ids))
-	for _, v := range root.children {
-		genIdByDFS(v, ids)
-	}
+func TestAnonymousWildcard(t *testing.T) {
+	Convey("AnonymousWildcard", t, func() {
+		paths := []string{
+			"/health/*/ready",
+			"/health/*/live",
+			"/users/:id/*/:action",
+		}
+		root := &node{}
+		for _, v := range paths {
+			root.Register([]byte(v), v)
+		}
+
+		h, param, _ := root.Lookup([]byte("/health/db/ready"))
+		So(h, ShouldEqual, "/health/*/ready")
+		So(len(param), ShouldEqual, 0)
+
+		h, param, _ = root.Lookup([]byte("/users/1/x/edit"))
+		So(h, ShouldEqual, "/users/:id/*/:action")
+		So(len(param), ShouldEqual, 2)
+		So(string(param[0].Key), ShouldEqual, "id")
+		So(string(param[1].Key), ShouldEqual, "action")
+		So(string(param[1].Value), ShouldEqual, "edit")
+
+		h, _, tsr := root.Lookup([]byte("/health/db/ready/"))
+		So(h, ShouldEqual, nil)
+		So(tsr, ShouldEqual, true)
+
+		So(func() { root.Register([]byte("/health/:name/ready"), "x") }, ShouldPanicWith, "'/health/:name/ready' conflict with the registered path '/health/*/live'")
+		So(func() { root.Register([]byte("/health/*all"), "x") }, ShouldPanicWith, "'/health/*all' conflict with the registered path '/health/*/live'")
+		So(func() { root.Register([]byte("/health/x/ready"), "x") }, ShouldPanicWith, "'/health/x/ready' conflict with the registered path '/health/*/live'")
+
+		// '*'之后不是名称字符时为匿名通配符及其后的静态字符，路径末尾的'*'同样为匿名通配符
+		root.Register([]byte("/feeds/*.json"), "/feeds/*.json")
+		root.Register([]byte("/any/*"), "/any/*")
+		h, param, _ = root.Lookup([]byte("/feeds/news.json"))
+		So(h, ShouldEqual, "/feeds/*.json")
+		So(len(param), ShouldEqual, 0)
+		h, _, _ = root.Lookup([]byte("/feeds/a/b.json"))
+		So(h, ShouldEqual, nil)
+		h, param, _ = root.Lookup([]byte("/any/x"))
+		So(h, ShouldEqual, "/any/*")
+		So(len(param), ShouldEqual, 0)
+		h, _, tsr = root.Lookup([]byte("/any/x/"))
+		So(h, ShouldEqual, nil)
+		So(tsr, ShouldEqual, true)
+
+		So(func() { root.Register([]byte("/feeds/*name"), "x") }, ShouldPanicWith, "'/feeds/*name' conflict with the registered path '/feeds/*.json'")
+		So(func() { root.Register([]byte("/a/*name.json"), "x") }, ShouldPanicWith, "the wildcard '*name' must not be followed by the static text '.json' in the same segment, wildcard names may only contain letters, digits and '_'")
+		So(func() { root.Register([]byte("/a/*:name"), "x") }, ShouldPanicWith, "the wildcard ':' must be separated from the previous one by a static character")
+	})
 }
 
-func render(n *node) string {
-	ids := make(map[*node]string)
-	genIdByDFS(n, ids)
-	return treeprint.Print(rNode{
-		ids: ids,
-		n:   n,
-	}, 4)
+func TestLookupAllocs(t *testing.T) {
+	Convey("LookupAllocs", t, func() {
+		root := &node{}
+		root.Register([]byte("/users/:id"), "user")
+		root.Register([]byte("/static/a"), "static")
+
+		// 不记录查找过程时，只有参数切片会产生内存分配
+		static, param := []byte("/static/a"), []byte("/users/42")
+		So(testing.AllocsPerRun(100, func() { root.Lookup(static) }), ShouldEqual, 0)
+		So(testing.AllocsPerRun(100, func() { root.Lookup(param) }), ShouldEqual, 1)
+	})
 }